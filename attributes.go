@@ -0,0 +1,72 @@
+package mcaccutils
+
+import (
+	"context"
+	"net/http"
+)
+
+const mcAttributesURL = "https://api.minecraftservices.com/player/attributes"
+
+// Attributes are the account settings of a player, as set by Microsoft
+// family settings and the player's own preferences.
+type Attributes struct {
+	// OnlineChat reports whether the player may use chat.
+	OnlineChat bool
+	// MultiplayerServer reports whether the player may join multiplayer
+	// servers.
+	MultiplayerServer bool
+	// MultiplayerRealms reports whether the player may join Realms.
+	MultiplayerRealms bool
+	// Telemetry and OptionalTelemetry report whether the player allows
+	// the game to send telemetry.
+	Telemetry         bool
+	OptionalTelemetry bool
+	// ProfanityFilter reports whether the player has the chat profanity
+	// filter turned on.
+	ProfanityFilter bool
+}
+
+type mojangPrivilege struct {
+	Enabled bool `json:"enabled"`
+}
+
+type mojangAttributes struct {
+	Privileges struct {
+		OnlineChat        mojangPrivilege `json:"onlineChat"`
+		MultiplayerServer mojangPrivilege `json:"multiplayerServer"`
+		MultiplayerRealms mojangPrivilege `json:"multiplayerRealms"`
+		Telemetry         mojangPrivilege `json:"telemetry"`
+		OptionalTelemetry mojangPrivilege `json:"optionalTelemetry"`
+	} `json:"privileges"`
+	ProfanityFilterPreferences struct {
+		ProfanityFilterOn bool `json:"profanityFilterOn"`
+	} `json:"profanityFilterPreferences"`
+}
+
+// GetAttributes returns the attributes of the player logged in to the
+// session s, which must be from LoginWithDeviceCode or RefreshMicrosoft.
+func (c *Client) GetAttributes(ctx context.Context, s *Session) (*Attributes, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mcAttributesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+	var dec mojangAttributes
+	if err := c.do(req, &dec); err != nil {
+		return nil, err
+	}
+	p := dec.Privileges
+	return &Attributes{
+		OnlineChat:        p.OnlineChat.Enabled,
+		MultiplayerServer: p.MultiplayerServer.Enabled,
+		MultiplayerRealms: p.MultiplayerRealms.Enabled,
+		Telemetry:         p.Telemetry.Enabled,
+		OptionalTelemetry: p.OptionalTelemetry.Enabled,
+		ProfanityFilter:   dec.ProfanityFilterPreferences.ProfanityFilterOn,
+	}, nil
+}
+
+// GetAttributes calls GetAttributes on the default client.
+func GetAttributes(ctx context.Context, s *Session) (*Attributes, error) {
+	return defaultClient.GetAttributes(ctx, s)
+}
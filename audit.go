@@ -0,0 +1,135 @@
+package mcaccutils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// An AuditEntry describes a single request sent to the API by a Client.
+// Requests answered from the response cache are not sent, so they have no
+// entry.
+type AuditEntry struct {
+	// Time is when the request was started, before any rate limit wait.
+	Time time.Time
+	// Method and URL are those of the request.
+	Method string
+	URL    string
+	// Query is the username or UUID being looked up, if the request was made
+	// by one of the client's lookups.
+	Query string
+	// Tag is the tag of the context of the lookup, as set by WithTag, if
+	// any.
+	Tag string
+	// Status is the status code of the response, or zero if no response was
+	// received.
+	Status int
+	// Err is the error which prevented a response being received, if any.
+	Err error
+	// RateLimitWait is the time the request spent waiting for the rate
+	// limiter and the concurrency limit.
+	RateLimitWait time.Duration
+	// Duration is the time between the request being sent and the response
+	// headers arriving. It does not include RateLimitWait.
+	Duration time.Duration
+}
+
+// queryKey is the context key under which the query of a lookup is stored in
+// its requests.
+type queryKey struct{}
+
+// withQuery returns a copy of ctx carrying the query of a lookup.
+func withQuery(ctx context.Context, query string) context.Context {
+	return context.WithValue(ctx, queryKey{}, query)
+}
+
+// queryFrom returns the query of the lookup a request was made for.
+func queryFrom(ctx context.Context) string {
+	query, _ := ctx.Value(queryKey{}).(string)
+	return query
+}
+
+// tagKey is the context key under which the tag set by WithTag is stored.
+type tagKey struct{}
+
+// WithTag returns a copy of ctx carrying tag, which labels the requests made
+// by lookups given the context, such as GetUUIDContext. Tags appear in the
+// audit log and are counted by the expvar metrics of the client, so a client
+// shared by several parts of a program can attribute its API usage to them,
+// for example with tags such as "login" and "sync". Tags should come from a
+// small fixed set, as each gets its own counter.
+func WithTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, tagKey{}, tag)
+}
+
+// TagFrom returns the tag set on ctx by WithTag, or an empty string.
+func TagFrom(ctx context.Context) string {
+	tag, _ := ctx.Value(tagKey{}).(string)
+	return tag
+}
+
+// audit reports a request sent through the client's Transport to its audit
+// hook, if it has one.
+func (c *Client) audit(req *http.Request, start time.Time, wait time.Duration, resp *http.Response, err error) {
+	f := c.Config().Audit
+	if f == nil {
+		return
+	}
+	e := AuditEntry{
+		Time:          start,
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		Query:         queryFrom(req.Context()),
+		Tag:           TagFrom(req.Context()),
+		Err:           err,
+		RateLimitWait: wait,
+		Duration:      time.Since(start) - wait,
+	}
+	if resp != nil {
+		e.Status = resp.StatusCode
+	}
+	f(e)
+}
+
+// auditRecord is the JSON form of an AuditEntry written by AuditLog.
+type auditRecord struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	URL           string    `json:"url"`
+	Query         string    `json:"query,omitempty"`
+	Tag           string    `json:"tag,omitempty"`
+	Status        int       `json:"status,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	RateLimitWait float64   `json:"rate_limit_wait"`
+	Duration      float64   `json:"duration"`
+}
+
+// AuditLog returns a function for Config.Audit which writes each entry to w
+// as a line of JSON. Durations are written in seconds. Writes are serialised,
+// so w does not need to be safe for concurrent use; errors writing to w are
+// ignored.
+func AuditLog(w io.Writer) func(e AuditEntry) {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e AuditEntry) {
+		r := auditRecord{
+			Time:          e.Time.UTC(),
+			Method:        e.Method,
+			URL:           e.URL,
+			Query:         e.Query,
+			Tag:           e.Tag,
+			Status:        e.Status,
+			RateLimitWait: e.RateLimitWait.Seconds(),
+			Duration:      e.Duration.Seconds(),
+		}
+		if e.Err != nil {
+			r.Error = e.Err.Error()
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(r)
+	}
+}
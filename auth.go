@@ -0,0 +1,230 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MojangAuthServerURL is the base URL of the Mojang authentication server.
+// Mojang accounts have been migrated to Microsoft accounts, so it only serves
+// as the default for Config.AuthServerURL; custom authentication servers
+// still use the same protocol.
+const MojangAuthServerURL = "https://authserver.mojang.com"
+
+// An AuthError is returned when an authentication server rejects a request.
+type AuthError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Type is the short name of the error, such as
+	// "ForbiddenOperationException".
+	Type string `json:"error"`
+	// Message describes the error.
+	Message string `json:"errorMessage"`
+	// Cause is the reason for the error, if the server gave one.
+	Cause string `json:"cause"`
+	// RetryAfter and RequestID are taken from the headers of the response,
+	// as for a StatusError.
+	RetryAfter time.Duration `json:"-"`
+	RequestID  string        `json:"-"`
+}
+
+// Is reports whether the error matches target. An AuthError for a request
+// rejected with 429 Too Many Requests matches ErrRateLimited.
+func (e *AuthError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
+
+// newAuthError returns an *AuthError for the response r, taking its details
+// from the headers.
+func newAuthError(r *http.Response) *AuthError {
+	se := statusError(r)
+	return &AuthError{StatusCode: r.StatusCode, RetryAfter: se.RetryAfter, RequestID: se.RequestID}
+}
+
+func (e *AuthError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("mcaccutils: authentication failed with status %d", e.StatusCode)
+	}
+	return "mcaccutils: " + e.Message
+}
+
+// A Session is the result of logging in to an authentication server. It has
+// JSON tags matching the server's responses, so it can be saved and loaded
+// as JSON.
+type Session struct {
+	// AccessToken authenticates the user to game servers and APIs.
+	AccessToken string `json:"accessToken"`
+	// ClientToken identifies the client the session belongs to. It must be
+	// sent along with AccessToken in later requests.
+	ClientToken string `json:"clientToken"`
+	// RefreshToken is the Microsoft refresh token of sessions from
+	// LoginWithDeviceCode, used by RefreshMicrosoft.
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// SelectedProfile is the profile the session plays as. Profiles from an
+	// authentication server have no properties.
+	SelectedProfile *Profile `json:"selectedProfile,omitempty"`
+	// AvailableProfiles are the profiles owned by the account.
+	AvailableProfiles []Profile `json:"availableProfiles,omitempty"`
+	// User describes the account, if the server sent it.
+	User *AuthUser `json:"user,omitempty"`
+}
+
+// An AuthUser is the account a Session belongs to.
+type AuthUser struct {
+	ID         string            `json:"id"`
+	Username   string            `json:"username"`
+	Properties []ProfileProperty `json:"properties,omitempty"`
+}
+
+// authServerURL returns the base URL of the authentication server.
+func (c *Client) authServerURL() string {
+	if u := c.Config().AuthServerURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return MojangAuthServerURL
+}
+
+// authRequest posts req as JSON to the endpoint of the authentication server
+// at path, decoding a successful response into resp if it is not nil. Error
+// responses are returned as an *AuthError.
+//
+// Logins are not player lookups, so they do not wait for the rate limiter,
+// and they are sent only once: a retried refresh would resend a token the
+// server may already have consumed.
+func (c *Client) authRequest(path string, req, resp interface{}) error {
+	endpoint := c.authServerURL() + path
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(context.Background(), unlimitedKey{}, true)
+	ctx = WithRetryPolicy(ctx, RetryPolicy{MaxAttempts: 1})
+	r, err := c.post(ctx, endpoint, "", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	if r.StatusCode != http.StatusOK && r.StatusCode != http.StatusNoContent {
+		authErr := newAuthError(r)
+		// The body is not always JSON, in which case the status is enough.
+		json.Unmarshal(body, authErr)
+		return authErr
+	}
+	if resp == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+type authAgent struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+type authTokens struct {
+	AccessToken string `json:"accessToken"`
+	ClientToken string `json:"clientToken"`
+}
+
+// Authenticate logs in to the authentication server with a username, which
+// is usually an email address, and a password. clientToken identifies the
+// client; if it is empty, the server generates one.
+func (c *Client) Authenticate(username, password, clientToken string) (*Session, error) {
+	req := struct {
+		Agent       authAgent `json:"agent"`
+		Username    string    `json:"username"`
+		Password    string    `json:"password"`
+		ClientToken string    `json:"clientToken,omitempty"`
+		RequestUser bool      `json:"requestUser"`
+	}{authAgent{"Minecraft", 1}, username, password, clientToken, true}
+	s := &Session{}
+	if err := c.authRequest("/authenticate", req, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Authenticate calls Authenticate on the default client.
+func Authenticate(username, password, clientToken string) (*Session, error) {
+	return defaultClient.Authenticate(username, password, clientToken)
+}
+
+// Refresh exchanges the tokens of s for a new session, invalidating the old
+// access token.
+func (c *Client) Refresh(s *Session) (*Session, error) {
+	req := struct {
+		authTokens
+		RequestUser bool `json:"requestUser"`
+	}{authTokens{s.AccessToken, s.ClientToken}, true}
+	ns := &Session{}
+	if err := c.authRequest("/refresh", req, ns); err != nil {
+		return nil, err
+	}
+	if ns.AvailableProfiles == nil {
+		ns.AvailableProfiles = s.AvailableProfiles
+	}
+	return ns, nil
+}
+
+// Refresh calls Refresh on the default client.
+func Refresh(s *Session) (*Session, error) {
+	return defaultClient.Refresh(s)
+}
+
+// Validate reports whether the access token of s can still be used. Tokens
+// which are no longer valid may still be refreshed.
+func (c *Client) Validate(s *Session) (valid bool, err error) {
+	err = c.authRequest("/validate", authTokens{s.AccessToken, s.ClientToken}, nil)
+	var authErr *AuthError
+	if errors.As(err, &authErr) && authErr.StatusCode == http.StatusForbidden {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Validate calls Validate on the default client.
+func Validate(s *Session) (valid bool, err error) {
+	return defaultClient.Validate(s)
+}
+
+// Signout invalidates every access token of the account with the given
+// username and password.
+func (c *Client) Signout(username, password string) error {
+	req := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{username, password}
+	return c.authRequest("/signout", req, nil)
+}
+
+// Signout calls Signout on the default client.
+func Signout(username, password string) error {
+	return defaultClient.Signout(username, password)
+}
+
+// InvalidateSession invalidates the access token of s.
+func (c *Client) InvalidateSession(s *Session) error {
+	return c.authRequest("/invalidate", authTokens{s.AccessToken, s.ClientToken}, nil)
+}
+
+// InvalidateSession calls InvalidateSession on the default client.
+func InvalidateSession(s *Session) error {
+	return defaultClient.InvalidateSession(s)
+}
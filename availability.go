@@ -0,0 +1,170 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// minNameWatchInterval is the shortest interval between two checks made by
+// WatchNames. The availability endpoint has a strict rate limit of its own.
+const minNameWatchInterval = 30 * time.Second
+
+// A NameStatus is the availability of a username, as reported by the
+// Minecraft services API.
+type NameStatus int
+
+const (
+	// NameAvailable means the name can be claimed.
+	NameAvailable NameStatus = iota
+	// NameTaken means another player has the name, or had it recently
+	// enough that it is still held for them.
+	NameTaken
+	// NameNotAllowed means the name is rejected by the Mojang filter, for
+	// example because it is offensive.
+	NameNotAllowed
+)
+
+// String returns the name of the status.
+func (s NameStatus) String() string {
+	switch s {
+	case NameAvailable:
+		return "available"
+	case NameTaken:
+		return "taken"
+	case NameNotAllowed:
+		return "not_allowed"
+	}
+	return "unknown"
+}
+
+// NameAvailability asks the Minecraft services API whether the specified
+// name can be claimed. The API only answers signed in players, so it needs a
+// session from LoginWithDeviceCode. Names no player can have get an
+// *InvalidNameError without contacting the API.
+func (c *Client) NameAvailability(ctx context.Context, s *Session, name string) (status NameStatus, err error) {
+	if err := checkName(name); err != nil {
+		return 0, err
+	}
+	endpoint := c.servicesURL() + "/minecraft/profile/name/" + url.PathEscape(name) + "/available"
+	req, err := http.NewRequestWithContext(withQuery(ctx, name), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return 0, err
+	}
+	switch resp.Status {
+	case "AVAILABLE":
+		return NameAvailable, nil
+	case "DUPLICATE":
+		return NameTaken, nil
+	case "NOT_ALLOWED":
+		return NameNotAllowed, nil
+	}
+	return 0, fmt.Errorf("mcaccutils: unknown name status %q", resp.Status)
+}
+
+// NameAvailability calls NameAvailability on the default client.
+func NameAvailability(ctx context.Context, s *Session, name string) (status NameStatus, err error) {
+	return defaultClient.NameAvailability(ctx, s, name)
+}
+
+// ValidateName reports whether the specified name would be accepted in a name
+// change, before the change is attempted, so registration tools can warn
+// their users. It returns an *InvalidNameError for names CheckNewName
+// rejects, without contacting the API, and an error matching
+// ErrNameNotAllowed for names the Mojang filter rejects. Names which are only
+// taken are valid; NameAvailability tells them apart. Like NameAvailability,
+// it needs the session of a signed in player.
+func (c *Client) ValidateName(ctx context.Context, s *Session, name string) error {
+	if err := CheckNewName(name); err != nil {
+		return err
+	}
+	status, err := c.NameAvailability(ctx, s, name)
+	if err != nil {
+		return err
+	}
+	if status == NameNotAllowed {
+		return fmt.Errorf("%w: %q", ErrNameNotAllowed, name)
+	}
+	return nil
+}
+
+// ValidateName calls ValidateName on the default client.
+func ValidateName(ctx context.Context, s *Session, name string) error {
+	return defaultClient.ValidateName(ctx, s, name)
+}
+
+// WatchNames starts a goroutine which checks the specified names in turn, one
+// every interval, publishing an EventNameAvailable event whenever one becomes
+// available, such as when a renamed player's old name is released. Subscribe
+// to the client to receive them. Intervals under 30 seconds are raised to
+// 30 seconds, and the interval doubles, up to eight times, while the API
+// reports that it is exceeded. Other errors are ignored, and the name is
+// checked again on the next round.
+//
+// The returned function stops the watcher, abandoning any check in progress.
+func (c *Client) WatchNames(s *Session, names []string, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go c.WatchNamesContext(ctx, s, names, interval)
+	return cancel
+}
+
+// WatchNamesContext is like WatchNames, but runs in the calling goroutine
+// until ctx is done, abandoning any check in progress. It returns an error
+// matching ErrCanceled.
+func (c *Client) WatchNamesContext(ctx context.Context, s *Session, names []string, interval time.Duration) error {
+	names = append([]string(nil), names...)
+	if interval < minNameWatchInterval {
+		interval = minNameWatchInterval
+	}
+	clock := orSystem(c.Config().Clock)
+	// available records the names seen available on their last check, so
+	// each release is only published once.
+	available := make(map[string]bool)
+	wait := interval
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return canceled(ctx)
+		case <-clock.After(wait):
+		}
+		if len(names) == 0 {
+			continue
+		}
+		name := names[i%len(names)]
+		status, err := c.NameAvailability(ctx, s, name)
+		if errors.Is(err, ErrRateLimited) {
+			if wait < 8*interval {
+				wait *= 2
+			}
+			continue
+		}
+		wait = interval
+		if err != nil {
+			continue
+		}
+		if status == NameAvailable && !available[name] {
+			c.publish(Event{Type: EventNameAvailable, Query: name, Name: name})
+		}
+		available[name] = status == NameAvailable
+	}
+}
+
+// WatchNames calls WatchNames on the default client.
+func WatchNames(s *Session, names []string, interval time.Duration) (stop func()) {
+	return defaultClient.WatchNames(s, names, interval)
+}
+
+// WatchNamesContext calls WatchNamesContext on the default client.
+func WatchNamesContext(ctx context.Context, s *Session, names []string, interval time.Duration) error {
+	return defaultClient.WatchNamesContext(ctx, s, names, interval)
+}
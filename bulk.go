@@ -0,0 +1,240 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A UUIDResult is the result of looking up one name with GetUUIDs.
+type UUIDResult struct {
+	// Query is the name which was looked up.
+	Query string
+	// UUID and Name are the undashed UUID and case corrected username of
+	// the player, as returned by GetUUID.
+	UUID string
+	Name string
+	// Err is the error which prevented the player being found, if any.
+	Err error
+}
+
+// batchSize returns the number of names looked up in each request.
+func (c *Client) batchSize() int {
+	if n := c.Config().BatchSize; n > 0 {
+		return n
+	}
+	return 10
+}
+
+// bulkConcurrency returns the number of lookups run at once by bulk
+// operations without a batch endpoint.
+func (c *Client) bulkConcurrency() int {
+	if n := c.Config().BulkConcurrency; n > 0 {
+		return n
+	}
+	return 4
+}
+
+// GetUUIDs looks up the players with the specified names, as GetUUID does,
+// but sends the names missing from the cache to the API in batches. It
+// returns a result for each name, in order.
+//
+// A failure only affects the names it concerns: names no player can have get
+// an *InvalidNameError and are never sent, and if a batch is rejected, for
+// example with an error matching ErrRateLimited, only the names in that batch
+// get the error and the remaining batches are still sent.
+//
+// Lookups made by GetUUIDs are recorded in the history of the client, but
+// are not repeated with its shadow provider.
+func (c *Client) GetUUIDs(names []string) []UUIDResult {
+	return c.GetUUIDsContext(context.Background(), names)
+}
+
+// GetUUIDsContext is like GetUUIDs, but stops sending batches when ctx is
+// done, including while waiting for the rate limiter. The names which were
+// not looked up by then have an error matching ErrCanceled.
+func (c *Client) GetUUIDsContext(ctx context.Context, names []string) []UUIDResult {
+	results := make([]UUIDResult, len(names))
+	// Indexes of the results waiting for each name missing from the cache.
+	missing := make(map[string][]int)
+	var batch []string
+	for i, n := range names {
+		results[i].Query = n
+		if err := checkName(n); err != nil {
+			// Sending the name would get the whole batch rejected.
+			results[i].Err = err
+			continue
+		}
+		k := strings.ToLower(n)
+		if p, found := c.cachedPlayer(k); found {
+			if p.notFound {
+				results[i].Err = ErrPlayerNotFound
+			} else {
+				results[i].UUID, results[i].Name = p.UUID, p.Username
+			}
+			continue
+		}
+		if _, ok := missing[k]; !ok {
+			batch = append(batch, k)
+		}
+		missing[k] = append(missing[k], i)
+	}
+	size := c.batchSize()
+	for len(batch) > 0 {
+		n := size
+		if n > len(batch) {
+			n = len(batch)
+		}
+		chunk := batch[:n]
+		batch = batch[n:]
+		err := canceled(ctx)
+		var players map[string]*playerCacheData
+		if err == nil {
+			players, err = c.fetchUUIDs(ctx, chunk)
+			if cerr := canceled(ctx); err != nil && cerr != nil {
+				err = cerr
+			}
+		}
+		for _, k := range chunk {
+			p, perr := players[k], err
+			if perr == nil && p == nil {
+				perr = ErrPlayerNotFound
+			}
+			if !errors.Is(perr, ErrCanceled) {
+				c.record(k, p, perr)
+				c.publishLookup(k, p, perr)
+			}
+			if errors.Is(perr, ErrPlayerNotFound) {
+				c.cacheNotFound(k)
+			} else if perr == nil {
+				c.cachePlayer(p)
+			}
+			for _, i := range missing[k] {
+				results[i].Err = perr
+				if p != nil {
+					results[i].UUID, results[i].Name = p.UUID, p.Username
+				}
+			}
+		}
+	}
+	return results
+}
+
+// GetUUIDs calls GetUUIDs on the default client.
+func GetUUIDs(names []string) []UUIDResult {
+	return defaultClient.GetUUIDs(names)
+}
+
+// GetUUIDsContext calls GetUUIDsContext on the default client.
+func GetUUIDsContext(ctx context.Context, names []string) []UUIDResult {
+	return defaultClient.GetUUIDsContext(ctx, names)
+}
+
+// A NameHistoryResult is the result of looking up one UUID with
+// GetNameHistories.
+type NameHistoryResult struct {
+	// History is the name history of the player, as returned by
+	// GetNameHistory.
+	History []NameChange
+	// Err is the error which prevented the history being fetched, if any.
+	Err error
+}
+
+// GetNameHistories fetches the name histories of the players with the
+// specified UUIDs, as GetNameHistory does. There is no batch endpoint for
+// name histories, so the lookups are run a few at a time, as set by
+// Config.BulkConcurrency, and each waits for the rate limiter. The results
+// are keyed by undashed UUID.
+func (c *Client) GetNameHistories(uuids []string) map[string]NameHistoryResult {
+	return c.GetNameHistoriesContext(context.Background(), uuids)
+}
+
+// GetNameHistoriesContext is like GetNameHistories, but stops when ctx is
+// done. The UUIDs which were not looked up by then have an error matching
+// ErrCanceled.
+func (c *Client) GetNameHistoriesContext(ctx context.Context, uuids []string) map[string]NameHistoryResult {
+	results := make(map[string]NameHistoryResult, len(uuids))
+	var mu sync.Mutex
+	work := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < c.bulkConcurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for uuid := range work {
+				var r NameHistoryResult
+				r.History, r.Err = c.getNameHistory(ctx, uuid)
+				if cerr := canceled(ctx); r.Err != nil && cerr != nil {
+					r.Err = cerr
+				}
+				mu.Lock()
+				results[uuid] = r
+				mu.Unlock()
+			}
+		}()
+	}
+	seen := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		uuid = strings.Replace(uuid, "-", "", -1)
+		if seen[uuid] {
+			continue
+		}
+		seen[uuid] = true
+		select {
+		case work <- uuid:
+		case <-ctx.Done():
+			mu.Lock()
+			results[uuid] = NameHistoryResult{Err: canceled(ctx)}
+			mu.Unlock()
+		}
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+// GetNameHistories calls GetNameHistories on the default client.
+func GetNameHistories(uuids []string) map[string]NameHistoryResult {
+	return defaultClient.GetNameHistories(uuids)
+}
+
+// GetNameHistoriesContext calls GetNameHistoriesContext on the default
+// client.
+func GetNameHistoriesContext(ctx context.Context, uuids []string) map[string]NameHistoryResult {
+	return defaultClient.GetNameHistoriesContext(ctx, uuids)
+}
+
+// A BulkEstimate is the expected cost of a bulk lookup.
+type BulkEstimate struct {
+	// Requests is the number of requests needed.
+	Requests int
+	// Duration is how long the requests are expected to take.
+	Duration time.Duration
+}
+
+// EstimateBulk estimates the cost of looking up n names with GetUUIDs, given
+// the current batch size and rate limit of the client, and the latency of
+// recent requests. It assumes none of the names are cached, so the real cost
+// is often lower.
+func (c *Client) EstimateBulk(n int) BulkEstimate {
+	size := c.batchSize()
+	e := BulkEstimate{Requests: (n + size - 1) / size}
+	e.Duration = c.limiter.Estimate(e.Requests)
+	// Requests are sent one after another, so their latency adds up.
+	u, err := url.Parse(c.bulkEndpoint())
+	if err != nil {
+		return e
+	}
+	if s, ok := c.latency.stats()[u.Host+u.Path]; ok {
+		e.Duration += time.Duration(e.Requests) * s.P50
+	}
+	return e
+}
+
+// EstimateBulk calls EstimateBulk on the default client.
+func EstimateBulk(n int) BulkEstimate {
+	return defaultClient.EstimateBulk(n)
+}
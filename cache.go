@@ -0,0 +1,254 @@
+package mcaccutils
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// A Store is an external cache for resolved players, such as a database
+// shared with other services or one which survives restarts. Values are
+// opaque to the store.
+type Store interface {
+	// Get returns the value stored under key. It reports found as false if
+	// there is no value, or if the value has expired.
+	Get(key string) (value []byte, found bool, err error)
+	// Set stores value under key until the expiry time.
+	Set(key string, value []byte, expires time.Time) error
+	// Delete removes the value stored under key, if any.
+	Delete(key string) error
+}
+
+// A StorePinger is a Store which can check that it is reachable, such as one
+// kept on a database server.
+type StorePinger interface {
+	Store
+	// Ping returns an error if the store cannot be used.
+	Ping(ctx context.Context) error
+}
+
+// PingStore checks that the external store of the client can be reached, for
+// readiness checks. Stores which do not implement StorePinger are assumed to
+// be reachable, as is the memory cache when there is no store.
+func (c *Client) PingStore(ctx context.Context) error {
+	p, ok := c.Config().Store.(StorePinger)
+	if !ok {
+		return nil
+	}
+	if err := p.Ping(ctx); err != nil {
+		return fmt.Errorf("mcaccutils: pinging store: %w", err)
+	}
+	return nil
+}
+
+// PingStore calls PingStore on the default client.
+func PingStore(ctx context.Context) error {
+	return defaultClient.PingStore(ctx)
+}
+
+type playerCacheData struct {
+	UUID     string    `json:"uuid"`
+	Username string    `json:"name"`
+	Expires  time.Time `json:"expires"`
+
+	// hits counts the number of times the entry has been read from the cache,
+	// and is used to find entries worth refreshing in the background.
+	hits int64
+	// notFound marks an entry recording that a lookup found no player.
+	notFound bool
+}
+
+// hit records a read of the entry from the cache.
+func (p *playerCacheData) hit() {
+	atomic.AddInt64(&p.hits, 1)
+}
+
+// cachedPlayer returns the player cached under k, looking in the memory cache
+// first and then in the external store.
+func (c *Client) cachedPlayer(k string) (*playerCacheData, bool) {
+	kind := CacheUUIDs
+	if len(k) == 32 {
+		kind = CacheNames
+	}
+	if p, found := c.cache.Get(k); found {
+		p.hit()
+		c.metrics.add("cache_hits", 1)
+		c.countCache(kind, k, true)
+		return p, true
+	}
+	p, found := c.storedPlayer(k)
+	if !found {
+		c.metrics.add("cache_misses", 1)
+		c.countCache(kind, k, false)
+		return nil, false
+	}
+	c.metrics.add("cache_hits", 1)
+	c.countCache(kind, k, true)
+	c.rememberPlayer(p)
+	return p, true
+}
+
+// storedPlayer returns the player stored under k in the external store.
+func (c *Client) storedPlayer(k string) (*playerCacheData, bool) {
+	store := c.Config().Store
+	if store == nil {
+		return nil, false
+	}
+	// Errors from the store are treated as a miss, so an unavailable store
+	// only costs an API request.
+	v, found, err := store.Get(k)
+	if err != nil || !found {
+		return nil, false
+	}
+	p := &playerCacheData{}
+	if err := c.codec().Unmarshal(v, p); err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+// cachePlayer stores a freshly fetched player in the memory cache and in the
+// external store, under both its UUID and its lowercased username, and saves
+// it to the player store.
+func (c *Client) cachePlayer(p *playerCacheData) {
+	p.Expires = c.now().Add(c.cacheTTL())
+	c.rememberPlayer(p)
+	c.savePlayer(p)
+	store := c.Config().Store
+	if store == nil {
+		return
+	}
+	v, err := c.codec().Marshal(p)
+	if err != nil {
+		return
+	}
+	store.Set(strings.ToLower(p.Username), v, p.Expires)
+	store.Set(p.UUID, v, p.Expires)
+}
+
+// Remember caches the player with the specified UUID and name, learned
+// elsewhere, such as from a server log, as if it had been looked up. The
+// mapping must come from a trustworthy source, as it is served from the cache
+// until it expires.
+func (c *Client) Remember(uuid, name string) {
+	c.cachePlayer(&playerCacheData{UUID: strings.Replace(uuid, "-", "", -1), Username: name})
+}
+
+// Remember calls Remember on the default client.
+func Remember(uuid, name string) {
+	defaultClient.Remember(uuid, name)
+}
+
+// cacheNotFound records in the memory cache that no player was found for the
+// key k.
+func (c *Client) cacheNotFound(k string) {
+	d := c.Config().NotFoundCacheDuration
+	if d <= 0 {
+		return
+	}
+	p := &playerCacheData{Expires: c.now().Add(d), notFound: true}
+	c.cache.Set(k, p, d)
+}
+
+// rememberPlayer stores p in the memory cache until it expires.
+func (c *Client) rememberPlayer(p *playerCacheData) {
+	ttl := p.Expires.Sub(c.now())
+	if ttl <= 0 {
+		return
+	}
+	c.cache.Set(strings.ToLower(p.Username), p, ttl)
+	c.cache.Set(p.UUID, p, ttl)
+}
+
+// cacheTTL returns the duration a newly fetched entry should be cached for.
+func (c *Client) cacheTTL() time.Duration {
+	cfg := c.Config()
+	if cfg.CacheJitter <= 0 {
+		return cfg.CacheDuration
+	}
+	return cfg.CacheDuration + time.Duration(rand.Int63n(int64(cfg.CacheJitter)))
+}
+
+// OnEvict registers f to be called whenever an entry is removed from the cache
+// before it has expired, for example by Invalidate.
+//
+// Every player is cached under both its UUID and its lowercased username, so f
+// is usually called twice for each player, once with each key.
+func (c *Client) OnEvict(f func(key, uuid, name string)) {
+	c.evictMu.Lock()
+	defer c.evictMu.Unlock()
+	c.onEvict = append(c.onEvict, f)
+}
+
+// OnEvict calls OnEvict on the default client.
+func OnEvict(f func(key, uuid, name string)) {
+	defaultClient.OnEvict(f)
+}
+
+// OnExpire registers f to be called whenever an expired entry is removed from
+// the cache. As with OnEvict, f is called once for each key of the player.
+//
+// Expired entries are cleared out periodically, so f may be called some time
+// after the entry actually expired.
+func (c *Client) OnExpire(f func(key, uuid, name string)) {
+	c.evictMu.Lock()
+	defer c.evictMu.Unlock()
+	c.onExpire = append(c.onExpire, f)
+}
+
+// OnExpire calls OnExpire on the default client.
+func OnExpire(f func(key, uuid, name string)) {
+	defaultClient.OnExpire(f)
+}
+
+// Invalidate removes the player with the specified name or UUID from the
+// cache and from the external store, so the next lookup fetches it from the
+// API again.
+func (c *Client) Invalidate(query string) {
+	k := strings.ToLower(strings.Replace(query, "-", "", -1))
+	c.profiles.Delete(k)
+	c.profiles.Delete(k + "/signed")
+	p, found := c.cachedPlayer(k)
+	if !found {
+		return
+	}
+	c.profiles.Delete(p.UUID)
+	c.profiles.Delete(p.UUID + "/signed")
+	if p.notFound {
+		c.cache.Delete(k)
+		return
+	}
+	store := c.Config().Store
+	keys := []string{strings.ToLower(p.Username), p.UUID}
+	for _, k := range keys {
+		c.cache.Delete(k)
+		if store != nil {
+			store.Delete(k)
+		}
+	}
+}
+
+// Invalidate calls Invalidate on the default client.
+func Invalidate(query string) {
+	defaultClient.Invalidate(query)
+}
+
+// evicted is called by the memory cache whenever an entry is removed, and
+// dispatches to the registered callbacks.
+func (c *Client) evicted(k string, p *playerCacheData) {
+	if p.notFound {
+		return
+	}
+	c.evictMu.RLock()
+	defer c.evictMu.RUnlock()
+	callbacks := c.onEvict
+	if !c.now().Before(p.Expires) {
+		callbacks = c.onExpire
+	}
+	for _, f := range callbacks {
+		f(k, p.UUID, p.Username)
+	}
+}
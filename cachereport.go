@@ -0,0 +1,217 @@
+package mcaccutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The caches of a Client, as named in a CacheReport.
+const (
+	// CacheUUIDs holds UUIDs looked up by name, with GetUUID.
+	CacheUUIDs = "uuids"
+	// CacheNames holds names looked up by UUID, with GetName.
+	CacheNames = "names"
+	// CacheResponses holds API responses, such as profiles, while
+	// Config.ResponseCacheDuration is set.
+	CacheResponses = "responses"
+	// CacheOptiFine holds the results of HasOptiFineCape.
+	CacheOptiFine = "optifine"
+	// CacheProfiles holds profiles, with GetProfile.
+	CacheProfiles = "profiles"
+	// CacheSkins holds texture images, with GetTexture.
+	CacheSkins = "skins"
+)
+
+// maxTrackedQueries bounds the number of queries whose lookups are counted
+// for CacheReport.TopQueries.
+const maxTrackedQueries = 10000
+
+// minReportLookups is the number of lookups of a cache below which no
+// suggestions are made about it, as its hit ratio means little.
+const minReportLookups = 100
+
+// cacheStats counts the hits and misses of the caches of a Client, and the
+// lookups of each query.
+type cacheStats struct {
+	mu      sync.Mutex
+	since   time.Time
+	hits    map[string]int64
+	misses  map[string]int64
+	queries map[string]int64
+}
+
+// add counts a lookup of query, which may be empty, in the cache kind.
+func (s *cacheStats) add(kind, query string, hit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hits == nil {
+		s.hits = make(map[string]int64)
+		s.misses = make(map[string]int64)
+		s.queries = make(map[string]int64)
+	}
+	if hit {
+		s.hits[kind]++
+	} else {
+		s.misses[kind]++
+	}
+	if query == "" {
+		return
+	}
+	if _, ok := s.queries[query]; !ok && len(s.queries) >= maxTrackedQueries {
+		// Halve every count, forgetting queries looked up once, so the
+		// counts favour recent lookups and room is made for new queries.
+		for q, n := range s.queries {
+			if n /= 2; n == 0 {
+				delete(s.queries, q)
+			} else {
+				s.queries[q] = n
+			}
+		}
+	}
+	s.queries[query]++
+}
+
+// countCache counts a lookup of query in the cache kind.
+func (c *Client) countCache(kind, query string, hit bool) {
+	c.cacheStats.add(kind, query, hit)
+}
+
+// CacheStats are the lookups made in one cache.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRatio returns the fraction of lookups answered by the cache, or zero if
+// there were none.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// A QueryCount is the number of times a player was looked up.
+type QueryCount struct {
+	// Query is the lowercased name or undashed UUID looked up.
+	Query    string
+	Requests int64
+}
+
+// A CacheReport summarises how well the caches of a Client work, so its
+// settings can be tuned with evidence.
+type CacheReport struct {
+	// Since is when counting started: when the client was created, or when
+	// the counts were last reset.
+	Since time.Time
+	// Caches holds the lookups of each cache, keyed by the Cache constants.
+	Caches map[string]CacheStats
+	// TopQueries are the most looked up players, most first. Counts are
+	// approximate once many players have been looked up.
+	TopQueries []QueryCount
+	// Suggestions are changes to the configuration the counts suggest.
+	Suggestions []string
+}
+
+// CacheEfficiency returns a report on the caches of the client, with the n
+// most looked up players. If reset is set the counts start again from zero.
+func (c *Client) CacheEfficiency(n int, reset bool) CacheReport {
+	s := &c.cacheStats
+	s.mu.Lock()
+	r := CacheReport{Since: s.since, Caches: make(map[string]CacheStats)}
+	for _, kind := range []string{CacheUUIDs, CacheNames, CacheResponses, CacheProfiles, CacheSkins, CacheOptiFine} {
+		r.Caches[kind] = CacheStats{Hits: s.hits[kind], Misses: s.misses[kind]}
+	}
+	for q, count := range s.queries {
+		r.TopQueries = append(r.TopQueries, QueryCount{q, count})
+	}
+	if reset {
+		s.since = c.now()
+		s.hits, s.misses, s.queries = nil, nil, nil
+	}
+	s.mu.Unlock()
+	sort.Slice(r.TopQueries, func(i, j int) bool {
+		a, b := r.TopQueries[i], r.TopQueries[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Query < b.Query
+	})
+	if len(r.TopQueries) > n {
+		r.TopQueries = r.TopQueries[:n]
+	}
+	r.Suggestions = c.suggest(r)
+	return r
+}
+
+// CacheEfficiency calls CacheEfficiency on the default client.
+func CacheEfficiency(n int, reset bool) CacheReport {
+	return defaultClient.CacheEfficiency(n, reset)
+}
+
+// suggest returns the configuration changes suggested by r.
+func (c *Client) suggest(r CacheReport) []string {
+	cfg := c.Config()
+	var out []string
+	low := func(kind string) bool {
+		s := r.Caches[kind]
+		return s.Hits+s.Misses >= minReportLookups && s.HitRatio() < 0.5
+	}
+	if (low(CacheUUIDs) || low(CacheNames)) && cfg.CacheDuration < 24*time.Hour {
+		out = append(out, fmt.Sprintf("Fewer than half of player lookups hit the cache: raise CacheDuration from %v.", cfg.CacheDuration))
+	}
+	if low(CacheResponses) {
+		if cfg.ResponseCacheDuration <= 0 {
+			out = append(out, "Responses are not cached: set ResponseCacheDuration to cache profiles and other responses.")
+		} else {
+			out = append(out, fmt.Sprintf("Fewer than half of responses hit the cache: raise ResponseCacheDuration from %v.", cfg.ResponseCacheDuration))
+		}
+	}
+	if low(CacheProfiles) {
+		out = append(out, fmt.Sprintf("Fewer than half of profile lookups hit the cache: raise ProfileCacheDuration from %v.", cfg.ProfileCacheDuration))
+	}
+	if low(CacheSkins) {
+		out = append(out, fmt.Sprintf("Fewer than half of texture downloads hit the cache: raise SkinCacheDuration from %v.", cfg.SkinCacheDuration))
+	}
+	if low(CacheOptiFine) {
+		out = append(out, fmt.Sprintf("Fewer than half of OptiFine cape checks hit the cache: raise OptiFineCacheDuration from %v.", cfg.OptiFineCacheDuration))
+	}
+	var total int64
+	for _, s := range r.Caches {
+		total += s.Hits + s.Misses
+	}
+	if len(r.TopQueries) > 0 && total >= minReportLookups && r.TopQueries[0].Requests*5 >= total {
+		out = append(out, fmt.Sprintf("%s makes up over a fifth of lookups: run StartRefresher so hot players never expire.", r.TopQueries[0].Query))
+	}
+	return out
+}
+
+// ReportCacheEfficiency starts a goroutine which calls f with a report on the
+// caches of the client every interval, as returned by CacheEfficiency(n, true),
+// so each report covers one interval.
+//
+// The returned function stops the reports.
+func (c *Client) ReportCacheEfficiency(interval time.Duration, n int, f func(r CacheReport)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f(c.CacheEfficiency(n, true))
+			}
+		}
+	}()
+	return cancel
+}
+
+// ReportCacheEfficiency calls ReportCacheEfficiency on the default client.
+func ReportCacheEfficiency(interval time.Duration, n int, f func(r CacheReport)) (stop func()) {
+	return defaultClient.ReportCacheEfficiency(interval, n, f)
+}
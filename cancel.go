@@ -0,0 +1,38 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCanceled is the error given for the work left undone by a bulk operation
+// or watcher whose context was canceled or reached its deadline. The errors
+// returned also match the error of the context, so errors.Is(err,
+// context.DeadlineExceeded) tells the two cases apart.
+var ErrCanceled = errors.New("mcaccutils: canceled")
+
+// canceledError is an ErrCanceled caused by the error of a context.
+type canceledError struct {
+	cause error
+}
+
+func (e *canceledError) Error() string {
+	return ErrCanceled.Error() + ": " + e.cause.Error()
+}
+
+func (e *canceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+func (e *canceledError) Unwrap() error {
+	return e.cause
+}
+
+// canceled returns an ErrCanceled caused by the error of ctx, or nil if ctx
+// is not done.
+func canceled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return &canceledError{cause: err}
+	}
+	return nil
+}
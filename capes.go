@@ -0,0 +1,57 @@
+package mcaccutils
+
+import "path"
+
+// A Cape is an official cape known to the package.
+type Cape struct {
+	// Alias is a short, stable identifier for the cape, such as
+	// "minecon2011".
+	Alias string
+	// Name is the human readable name of the cape.
+	Name string
+}
+
+// capes maps the texture hashes of official capes to their descriptions.
+var capes = map[string]Cape{
+	"2340c0e03dd24a11b15a8b33c2a7e9e32abb2051b2481d0ba7defd635ca7a933": {"migrator", "Migrator"},
+	"f9a76537647989f9a0b6d001e320dac591c359e9e61a31f4ce11c88f207f0ad4": {"vanilla", "Vanilla"},
+	"953cac8b779fe41383e675ee2b86071a71658f2180f56fbce8aa315ea70e2ed6": {"minecon2011", "MineCon 2011"},
+	"a2e8d97ec79100e90a75d369d1b3ba81273c4f82bc1b737e934eed4a854be1b6": {"minecon2012", "MineCon 2012"},
+	"153b1a0dfcbae953cdeb6f2c2bf6bf79943239b1372780da44bcbb29273131da": {"minecon2013", "MineCon 2013"},
+	"b0cc08840700447322d953a02b965f1d65a13a603bf64b17c803c21446fe1635": {"minecon2015", "MineCon 2015"},
+	"e7dfea16dc83c97df01a12fabbd1216359c0cd0ea42f9999b6e97c584963e980": {"minecon2016", "MineCon 2016"},
+	"5786fe99be377dfb6858859f926c4dbc995751e91cee373468c5fbf4865e7151": {"mojang", "Mojang"},
+	"ca35c56efe71ed290385f4ab5346a1826b546a54d519e6a3ff01efa01acce81":  {"cobalt", "Cobalt"},
+	"1bf91499701404e21bd46b0191d63239a4ef76ebde88d27e4d430ac211df681e": {"translator", "Translator"},
+	"3efadf6510961830f9fcc077f19b4daf286d502b5f5aafbd807c7bbffcaca245": {"scrolls", "Scrolls"},
+	"70efffaf86fe5bc089608d3cb297d3e276b9eb7a8f9f2fe6659c23a2d8b18edf": {"millionth_customer", "Millionth Customer"},
+	"28de4a81688ad18b49e735a273e086c18f1e3966956123ccb574034c06f5d336": {"pan", "Pan"},
+	"cd9d82ab17fd92022dbd4a86cde4c382a7540e117fae7b9a2853658505a80625": {"15th_anniversary", "15th Anniversary"},
+	"afd553b39358a24edfe3b8a9a939fa5fa4faa4d9a9c3d6af8eafb377fa05c2bb": {"cherry_blossom", "Cherry Blossom"},
+}
+
+// RegisterCape adds a cape to the catalog used by IdentifyCape, or replaces
+// the description of a known one. It allows programs to recognise capes
+// released after the package. It is not safe to call concurrently with
+// IdentifyCape, so it should be called during initialisation.
+func RegisterCape(hash string, cape Cape) {
+	capes[hash] = cape
+}
+
+// IdentifyCape returns the official cape with the texture at the given URL.
+// It reports ok as false if the cape is not in the catalog.
+func IdentifyCape(url string) (cape Cape, ok bool) {
+	cape, ok = capes[path.Base(url)]
+	return cape, ok
+}
+
+// IdentifyCape returns the official cape worn by the player, as with the
+// package level IdentifyCape. It reports ok as false if the player has no
+// cape, or if it is not in the catalog.
+func (p *Profile) IdentifyCape() (cape Cape, ok bool) {
+	t, err := p.Textures()
+	if err != nil || t.Cape == nil {
+		return Cape{}, false
+	}
+	return IdentifyCape(t.Cape.URL)
+}
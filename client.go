@@ -0,0 +1,511 @@
+package mcaccutils
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds the settings of a Client. The Config is copied when the Client
+// is created, so changing it afterwards has no effect on the Client.
+type Config struct {
+	// HTTPClient is used to make all requests to the Mojang API, with its
+	// transport wrapped by the client's Transport. If it is nil, a zero
+	// http.Client is used.
+	HTTPClient *http.Client
+
+	// CacheDuration is the duration fetched names and UUIDs are cached for.
+	// Making this duration very short can make it much easier to go over the
+	// Mojang rate limits, so it is not recommended. Zero disables caching
+	// names and UUIDs, including players given to Remember, so every lookup
+	// makes a request; DefaultConfig sets it to 12 hours.
+	CacheDuration time.Duration
+
+	// CacheJitter is the upper bound of a random duration added to
+	// CacheDuration whenever an entry is cached. It spreads out the expiry of
+	// players that were looked up at the same time (for example when a server
+	// starts), so they are not all fetched again at once. Zero disables
+	// jitter.
+	CacheJitter time.Duration
+
+	// NotFoundCacheDuration is the duration a failed lookup is cached for, so
+	// repeated queries for a player that does not exist are answered without
+	// hitting the API. It should be kept short, as names which are not in use
+	// can be claimed at any time. Zero disables caching failed lookups.
+	NotFoundCacheDuration time.Duration
+
+	// Store, if set, is used as a second level cache behind the memory cache.
+	// It is checked whenever a lookup misses the memory cache, and every
+	// player fetched from the API is saved to it.
+	Store Store
+
+	// StoreCodec is the Codec used to encode the values saved to Store. Every
+	// service sharing a store must use the same codec. If it is nil,
+	// JSONCodec is used.
+	StoreCodec Codec
+
+	// RefreshThreshold is the number of cache hits after which an entry is
+	// considered hot, and is refreshed by the background refresher before it
+	// expires.
+	RefreshThreshold int64
+
+	// RefreshWindow is how long before its expiry a hot entry becomes
+	// eligible for a background refresh.
+	RefreshWindow time.Duration
+
+	// RefreshInterval is the minimum time between two requests made by the
+	// background refresher. Keeping it long leaves room under the Mojang rate
+	// limits for lookups that miss the cache. Zero means five seconds.
+	RefreshInterval time.Duration
+
+	// RateLimit is the number of requests the client may make in each
+	// RateLimitPeriod. Requests over the limit wait until they are allowed.
+	// Zero disables rate limiting.
+	RateLimit int
+
+	// RateLimitPeriod is the period RateLimit applies to.
+	RateLimitPeriod time.Duration
+
+	// AdaptiveRateLimit makes the client learn the limit it can actually use,
+	// starting from RateLimit. The limit is halved whenever the API responds
+	// with 429 Too Many Requests, and slowly raised again while requests
+	// succeed. This helps when the Mojang limits change, or when several
+	// processes share an IP address.
+	AdaptiveRateLimit bool
+
+	// MaxRateLimit is the highest limit an adaptive rate limiter may raise
+	// the limit to. If it is below RateLimit, RateLimit is used.
+	MaxRateLimit int
+
+	// ResponseCacheDuration is the duration successful GET responses are
+	// cached for by the client's Transport. Zero disables the response
+	// cache.
+	ResponseCacheDuration time.Duration
+
+	// Middleware wraps every API request made by the client, in order, so the
+	// first middleware sees each request first. The client's Transport is
+	// below the middleware, so requests answered from the response cache
+	// still pass through it.
+	Middleware []Middleware
+
+	// Shadow, if set, is sent a copy of every lookup the client makes to the
+	// API, and its results are compared to the client's own. It is meant for
+	// checking that a mirror or third party service agrees with Mojang before
+	// relying on it. Shadow lookups run in the background and never change
+	// the client's results.
+	Shadow Provider
+
+	// OnDivergence is called with the details of every lookup for which
+	// Shadow returned a different result to the client. It is called from
+	// a separate goroutine.
+	OnDivergence func(d Divergence)
+
+	// ExpvarName, if set, publishes the counters of the client with expvar
+	// as a map of that name. The map holds api_calls, cache_hits and
+	// cache_misses, the number of failed lookups of each type under errors,
+	// and the latency of each endpoint under latency. Names must be unique
+	// within a process.
+	ExpvarName string
+
+	// TLSConfig, if set, is used for connections to the API. The transport
+	// of HTTPClient must be nil or an *http.Transport for it to be applied.
+	TLSConfig *tls.Config
+
+	// PinnedKeys maps host names to the pins of the public keys which may
+	// appear in their certificate chains, as returned by PublicKeyPin.
+	// Connections to a host with pins fail with a *PinError unless one of the
+	// certificates presented matches a pin. As with TLSConfig, the transport
+	// of HTTPClient must be nil or an *http.Transport.
+	PinnedKeys map[string][]string
+
+	// MaxIdleConnsPerHost is the number of idle connections kept open to each
+	// API host for reuse. High throughput clients should raise it well above
+	// the net/http default of two, so they do not keep setting up new TLS
+	// sessions. Zero keeps the setting of the transport.
+	//
+	// This and the connection settings below are only applied if the
+	// transport of HTTPClient is nil or an *http.Transport.
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept open. Zero keeps
+	// the setting of the transport.
+	IdleConnTimeout time.Duration
+
+	// TCPKeepAlive is the interval between TCP keep-alive probes on new
+	// connections. Zero keeps the setting of the transport, and a negative
+	// value disables the probes.
+	TCPKeepAlive time.Duration
+
+	// DisableKeepAlives makes every request use a new connection.
+	DisableKeepAlives bool
+
+	// DNSCacheDuration, if set, makes the client cache the addresses of the
+	// API hosts for that long, whatever the TTL of their DNS records. Bulk
+	// jobs on hosts with slow resolvers otherwise spend a surprising amount
+	// of time resolving the same few names.
+	DNSCacheDuration time.Duration
+
+	// History, if set, records every lookup the client resolves through the
+	// API, whether or not a player was found. Lookups answered from the cache
+	// are not recorded.
+	History History
+
+	// Retry is the policy for retrying failed requests. The zero value
+	// sends every request once. WithRetryPolicy overrides it for a single
+	// lookup.
+	Retry RetryPolicy
+
+	// Simulation, if set, makes the client behave as if the API throttled
+	// it, for testing how programs cope with rate limiting.
+	Simulation *Simulation
+
+	// OwnerSources are asked by PreviousOwners which players have used a
+	// name, after the history if it is an OwnerSource.
+	OwnerSources []OwnerSource
+
+	// Audit, if set, is called after every request the client sends to the
+	// API, with the details needed for capacity planning and investigating
+	// abuse. AuditLog returns a suitable function which writes to a file. It
+	// is called synchronously, so it should return quickly.
+	Audit func(e AuditEntry)
+
+	// BatchSize is the number of names looked up in each request by
+	// GetUUIDs. The Mojang API allows at most 10. Zero means 10.
+	BatchSize int
+
+	// StrictJSON makes the client reject responses from the lookup
+	// endpoints with fields the package does not know about, so tests catch
+	// changes to the API early. By default unknown fields are ignored.
+	StrictJSON bool
+
+	// Clock, if set, tells the time used to expire cache entries and refill
+	// the rate limiter, so tests can fast-forward through them with a
+	// ManualClock. Nil means the system clock.
+	Clock Clock
+
+	// BulkConcurrency is the number of lookups GetNameHistories runs at once.
+	// Each still waits for the rate limiter. Zero means 4.
+	BulkConcurrency int
+
+	// MaxConcurrentRequests is the number of requests the client may have in
+	// flight to each endpoint at once, whatever the rate limit allows.
+	// Requests over the limit wait for an earlier one to finish. It keeps
+	// large bulk jobs from running out of file descriptors or looking like
+	// abuse to the API. Zero means no limit.
+	MaxConcurrentRequests int
+
+	// APIURL is the base URL of the account API, used to look up UUIDs and
+	// name histories. If it is empty, the Mojang API is used.
+	APIURL string
+
+	// SessionServerURL is the base URL of the session server, used to fetch
+	// profiles. If it is empty, the Mojang session server is used.
+	SessionServerURL string
+
+	// AuthServerURL is the base URL of the authentication server, used to
+	// log in with Authenticate and manage sessions. If it is empty, the
+	// Mojang authentication server is used.
+	AuthServerURL string
+
+	// ServicesURL is the base URL of the Minecraft services API, which
+	// LookupService may select for looking up UUIDs and which Microsoft
+	// logins finish at. If it is empty, MinecraftServicesURL is used.
+	ServicesURL string
+
+	// LookupService selects the API used to look up UUIDs by name. The zero
+	// value uses the account API at APIURL.
+	LookupService LookupService
+
+	// FailoverThreshold is the number of lookups in a row which must fail
+	// for LookupFailover to switch to the Minecraft services API. Zero
+	// means 3.
+	FailoverThreshold int
+	// FailoverCheckInterval is how often LookupFailover tries the account
+	// API again while it is failed over. Zero means one minute.
+	FailoverCheckInterval time.Duration
+
+	// OptiFineCacheDuration is the duration the results of HasOptiFineCape
+	// are cached for. Zero disables caching them.
+	OptiFineCacheDuration time.Duration
+
+	// ProfileCacheDuration is the duration the profiles returned by
+	// GetProfile are cached for, separately from names and UUIDs, as skins
+	// and capes change more often than names. Zero disables caching them,
+	// leaving only the response cache.
+	ProfileCacheDuration time.Duration
+
+	// SkinCacheDuration is the duration the texture images downloaded by
+	// GetTexture are cached for. Images never change, so it only bounds the
+	// memory they take. Zero disables caching them.
+	SkinCacheDuration time.Duration
+
+	// AvatarURL is the template of the avatar URLs in profile summaries, in
+	// which {uuid} is replaced by the undashed UUID of the player and {name}
+	// by their name, such as the face render of a self hosted service. If it
+	// is empty, DefaultAvatarURL is used.
+	AvatarURL string
+
+	// PlayerStore, if set, is given every player the client resolves, so
+	// they can be kept in a database of the program's own. LoadPlayer reads
+	// them back.
+	PlayerStore PlayerStore
+
+	// LinkProvider finds the Bedrock Edition accounts linked to Java Edition
+	// accounts and back. Nil means the GeyserMC global linking API at
+	// LinkURL.
+	LinkProvider LinkProvider
+	// LinkURL is the base URL of the GeyserMC global linking API. If it is
+	// empty, GeyserLinkURL is used.
+	LinkURL string
+
+	// MicrosoftLoginURL is the base URL of the Microsoft identity platform,
+	// used by LoginWithDeviceCode and RefreshMicrosoft. If it is empty, the
+	// MicrosoftLoginURL constant is used.
+	MicrosoftLoginURL string
+	// XboxUserAuthURL and XSTSAuthURL are the base URLs of the Xbox Live
+	// services which exchange a Microsoft token for a Minecraft one. If they
+	// are empty, the XboxUserAuthURL and XSTSAuthURL constants are used.
+	XboxUserAuthURL string
+	XSTSAuthURL     string
+
+	// RealmsURL is the base URL of the Minecraft Realms API. If it is empty,
+	// the RealmsURL constant is used.
+	RealmsURL string
+	// RealmsVersion is the game version reported to Realms, which turns away
+	// clients older than the latest release. If it is empty,
+	// DefaultRealmsVersion is used.
+	RealmsVersion string
+}
+
+// DefaultConfig returns the recommended configuration, which callers can
+// adjust before passing it to NewClient.
+func DefaultConfig() Config {
+	return Config{
+		CacheDuration:         12 * time.Hour,
+		CacheJitter:           30 * time.Minute,
+		NotFoundCacheDuration: 10 * time.Minute,
+		StoreCodec:            JSONCodec,
+		RefreshThreshold:      10,
+		RefreshWindow:         30 * time.Minute,
+		RefreshInterval:       5 * time.Second,
+		RateLimit:             600,
+		RateLimitPeriod:       10 * time.Minute,
+		ResponseCacheDuration: 1 * time.Minute,
+		OptiFineCacheDuration: 1 * time.Hour,
+		ProfileCacheDuration:  5 * time.Minute,
+		SkinCacheDuration:     1 * time.Hour,
+		BatchSize:             10,
+		BulkConcurrency:       4,
+		APIURL:                MojangAPIURL,
+		SessionServerURL:      MojangSessionServerURL,
+		AuthServerURL:         MojangAuthServerURL,
+	}
+}
+
+// The base URLs of the Mojang services.
+const (
+	MojangAPIURL           = "https://api.mojang.com"
+	MojangSessionServerURL = "https://sessionserver.mojang.com"
+	MinecraftServicesURL   = "https://api.minecraftservices.com"
+)
+
+// AuthlibInjectorConfig returns the recommended configuration for using the
+// authlib-injector compatible authentication server at the API root root, such
+// as a Drasl or Blessing Skin server, instead of Mojang.
+func AuthlibInjectorConfig(root string) Config {
+	root = strings.TrimSuffix(root, "/")
+	cfg := DefaultConfig()
+	cfg.APIURL = root + "/api"
+	cfg.SessionServerURL = root + "/sessionserver"
+	cfg.AuthServerURL = root + "/authserver"
+	return cfg
+}
+
+// A Client looks up players using the Mojang API and caches the results. Each
+// Client has its own configuration and memory cache, so separate components
+// of a program can use differently configured Clients without interfering
+// with each other.
+//
+// Clients are safe for concurrent use.
+type Client struct {
+	cfg Config
+	// legacy marks the default client, which reads its configuration from
+	// the deprecated package variables each time it is used.
+	legacy bool
+
+	// cache is the memory cache for all players, keyed by both lowercased
+	// username and undashed UUID.
+	cache *typedCache[*playerCacheData]
+	// responses is the response cache of the client's Transport, keyed by
+	// URL.
+	responses *typedCache[*cachedResponse]
+	// optifine caches whether players have OptiFine capes, keyed by
+	// lowercased username.
+	optifine *typedCache[bool]
+	// profiles caches the profiles returned by GetProfile, keyed by undashed
+	// UUID.
+	profiles *typedCache[*Profile]
+	// textures caches texture images, keyed by texture hash.
+	textures *typedCache[[]byte]
+
+	// limiter limits the rate of all requests made through the client's
+	// Transport.
+	limiter *limiter
+	// inflight limits the number of requests in flight to each endpoint.
+	inflight *endpointLimiter
+	// doer is used for the client's own requests. It is the middleware chain
+	// wrapped around an http.Client using the client's Transport.
+	doer Doer
+
+	// metrics holds the expvar counters of the client, if they are enabled.
+	metrics *metrics
+	// latency holds the latencies of recent requests to each endpoint.
+	latency latencies
+
+	// evictMu guards the callback lists below.
+	evictMu  sync.RWMutex
+	onEvict  []func(key, uuid, name string)
+	onExpire []func(key, uuid, name string)
+
+	// subMu guards the event subscribers, which are keyed by an id so they
+	// can be removed.
+	subMu   sync.RWMutex
+	subs    map[int]func(e Event)
+	nextSub int
+
+	// skinMu guards skins, which maps UUIDs to the hash of the skin last seen
+	// by HasSkinChanged.
+	skinMu sync.Mutex
+	skins  map[string]string
+
+	// retiredMu guards retired, which holds the endpoints the API has been
+	// found to no longer serve.
+	retiredMu sync.RWMutex
+	retired   map[retiredEndpoint]bool
+
+	// failover tracks the health of the account API for LookupFailover.
+	failover failover
+
+	// cacheStats counts cache hits and misses for CacheEfficiency.
+	cacheStats cacheStats
+}
+
+// defaultClient is the Client used by the package level functions.
+var defaultClient = newDefaultClient()
+
+// NewClient returns a Client with the specified configuration.
+func NewClient(cfg Config) *Client {
+	clock := orSystem(cfg.Clock)
+	c := &Client{
+		cfg:       cfg,
+		cache:     newTypedCache[*playerCacheData](clock, 1*time.Minute),
+		responses: newTypedCache[*cachedResponse](clock, 1*time.Minute),
+		optifine:  newTypedCache[bool](clock, 1*time.Minute),
+		profiles:  newTypedCache[*Profile](clock, 1*time.Minute),
+		textures:  newTypedCache[[]byte](clock, 1*time.Minute),
+		limiter:   newLimiter(clock, cfg.RateLimit, cfg.RateLimitPeriod, cfg.AdaptiveRateLimit, cfg.MaxRateLimit),
+		metrics:   newMetrics(cfg.ExpvarName),
+		inflight:  newEndpointLimiter(cfg.MaxConcurrentRequests),
+	}
+	c.cacheStats.since = clock.Now()
+	c.cache.OnEvicted(c.evicted)
+	c.metrics.publishLatency(c.latency.stats)
+	hc := http.Client{}
+	if cfg.HTTPClient != nil {
+		hc = *cfg.HTTPClient
+	}
+	hc.Transport = c.Transport(simulate(cfg.Simulation, clock, baseTransport(cfg, hc.Transport)))
+	middleware := append(append([]Middleware(nil), cfg.Middleware...), platformMiddleware...)
+	c.doer = chain(&hc, middleware)
+	return c
+}
+
+func newDefaultClient() *Client {
+	c := NewClient(DefaultConfig())
+	c.legacy = true
+	return c
+}
+
+// Config returns the configuration of the client.
+func (c *Client) Config() Config {
+	cfg := c.cfg
+	if c.legacy {
+		cfg.CacheDuration = CacheDuration
+		cfg.CacheJitter = CacheJitter
+		cfg.NotFoundCacheDuration = NotFoundCacheDuration
+		cfg.Store = ExternalStore
+		cfg.StoreCodec = StoreCodec
+		cfg.RefreshThreshold = RefreshThreshold
+		cfg.RefreshWindow = RefreshWindow
+		cfg.RefreshInterval = RefreshInterval
+	}
+	return cfg
+}
+
+// RateLimit returns the number of requests per rate limit period the client
+// currently allows, or zero if it is not rate limited. With adaptive rate
+// limiting, it changes as the client learns the real limit.
+func (c *Client) RateLimit() int {
+	return c.limiter.Limit()
+}
+
+// apiURL returns the base URL of the account API.
+func (c *Client) apiURL() string {
+	if u := c.Config().APIURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return MojangAPIURL
+}
+
+// servicesURL returns the base URL of the Minecraft services API.
+func (c *Client) servicesURL() string {
+	if u := c.Config().ServicesURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return MinecraftServicesURL
+}
+
+// now returns the current time as told by the clock of the client.
+func (c *Client) now() time.Time {
+	return orSystem(c.cfg.Clock).Now()
+}
+
+// sessionServerURL returns the base URL of the session server.
+func (c *Client) sessionServerURL() string {
+	if u := c.Config().SessionServerURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return MojangSessionServerURL
+}
+
+// get sends a GET request for url, made to look up query.
+func (c *Client) get(ctx context.Context, url, query string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(withQuery(ctx, query), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.doer.Do(req)
+}
+
+// post sends a POST request to url with the given body, made to look up
+// query.
+func (c *Client) post(ctx context.Context, url, query, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(withQuery(ctx, query), http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.doer.Do(req)
+}
+
+// codec returns the Codec values in the external store are encoded with.
+func (c *Client) codec() Codec {
+	if codec := c.Config().StoreCodec; codec != nil {
+		return codec
+	}
+	return JSONCodec
+}
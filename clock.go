@@ -0,0 +1,98 @@
+package mcaccutils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Clock tells the time for a Client, which uses it to expire cache entries
+// and refill the rate limiter. Tests can use a ManualClock to fast-forward
+// through cache durations and rate limit periods without waiting.
+//
+// Expired entries are still cleared out of memory in real time, and the
+// latency of requests is always measured with the system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel which receives the time once d has passed, as
+	// time.After does.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock used when Config.Clock is nil.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// orSystem returns c, or the system clock if c is nil.
+func orSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
+
+// A ManualClock is a Clock which only moves when told to. It is safe for
+// concurrent use.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+// manualWaiter is a channel returned by ManualClock.After, waiting for the
+// clock to reach at.
+type manualWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewManualClock returns a ManualClock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now implements Clock.
+func (m *ManualClock) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// After implements Clock. The channel receives once Advance or Set moves the
+// clock d or more past the current time.
+func (m *ManualClock) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- m.now
+		return ch
+	}
+	m.waiters = append(m.waiters, manualWaiter{at: m.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d.
+func (m *ManualClock) Advance(d time.Duration) {
+	m.mu.Lock()
+	t := m.now.Add(d)
+	m.mu.Unlock()
+	m.Set(t)
+}
+
+// Set moves the clock to t, firing every channel returned by After which is
+// due by then, earliest first.
+func (m *ManualClock) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = t
+	sort.Slice(m.waiters, func(i, j int) bool { return m.waiters[i].at.Before(m.waiters[j].at) })
+	i := 0
+	for ; i < len(m.waiters) && !m.waiters[i].at.After(t); i++ {
+		m.waiters[i].ch <- t
+	}
+	m.waiters = m.waiters[i:]
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/bearbin/go-mcaccutils/providers"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the configuration read from the file named by the -config
+// flag. Empty fields leave the defaults of the package alone.
+type fileConfig struct {
+	// Listen is the address serve listens on when none is given.
+	Listen string `yaml:"listen"`
+	// Provider is the account system looked up: mojang, elyby, littleskin,
+	// or the root URL of an authlib-injector API.
+	Provider string `yaml:"provider"`
+	// LookupService is the Mojang API used for UUID lookups: api, services,
+	// fallback or failover.
+	LookupService string `yaml:"lookupService"`
+
+	// Cache configures the player cache.
+	Cache struct {
+		// Backend is where players are cached besides memory: memory or
+		// sqlite.
+		Backend string `yaml:"backend"`
+		// Path is the SQLite database used by the sqlite backend.
+		Path             string        `yaml:"path"`
+		Duration         time.Duration `yaml:"duration"`
+		NotFoundDuration time.Duration `yaml:"notFoundDuration"`
+	} `yaml:"cache"`
+	// RateLimit configures the rate limit of requests to the API.
+	RateLimit struct {
+		Requests int           `yaml:"requests"`
+		Period   time.Duration `yaml:"period"`
+		Adaptive bool          `yaml:"adaptive"`
+		Max      int           `yaml:"max"`
+	} `yaml:"rateLimit"`
+
+	// History is the SQLite database lookups are recorded in.
+	History string `yaml:"history"`
+	// Keys is the JSON file of the API keys of the mirror.
+	Keys string `yaml:"keys"`
+	// ConsumerRateLimit is the number of requests a minute each consumer of
+	// the mirror may make.
+	ConsumerRateLimit int `yaml:"consumerRateLimit"`
+	// CheckUpstream makes the readiness check of the mirror also check the
+	// upstream APIs.
+	CheckUpstream bool `yaml:"checkUpstream"`
+
+	// Render configures the skin renders of the mirror.
+	Render struct {
+		Enabled bool `yaml:"enabled"`
+		// Cache is the directory downloaded skins are kept in.
+		Cache string `yaml:"cache"`
+	} `yaml:"render"`
+}
+
+// loadConfig reads the configuration file at path, if path is not empty,
+// and then applies the MCACCUTILS_* environment variables on top of it.
+func loadConfig(path string) (*fileConfig, error) {
+	fc := new(fileConfig)
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("mcaccutils: reading config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, fc); err != nil {
+			return nil, fmt.Errorf("mcaccutils: parsing config %s: %w", path, err)
+		}
+	}
+	if err := fc.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// applyEnv overrides the fields of fc with the environment variables found
+// by lookup.
+func (fc *fileConfig) applyEnv(lookup func(key string) (string, bool)) error {
+	str := func(p *string) func(string) error {
+		return func(v string) error { *p = v; return nil }
+	}
+	num := func(p *int) func(string) error {
+		return func(v string) (err error) { *p, err = strconv.Atoi(v); return err }
+	}
+	boolean := func(p *bool) func(string) error {
+		return func(v string) (err error) { *p, err = strconv.ParseBool(v); return err }
+	}
+	dur := func(p *time.Duration) func(string) error {
+		return func(v string) (err error) { *p, err = time.ParseDuration(v); return err }
+	}
+	overrides := []struct {
+		key string
+		set func(v string) error
+	}{
+		{"MCACCUTILS_LISTEN", str(&fc.Listen)},
+		{"MCACCUTILS_PROVIDER", str(&fc.Provider)},
+		{"MCACCUTILS_LOOKUP_SERVICE", str(&fc.LookupService)},
+		{"MCACCUTILS_CACHE_BACKEND", str(&fc.Cache.Backend)},
+		{"MCACCUTILS_CACHE_PATH", str(&fc.Cache.Path)},
+		{"MCACCUTILS_CACHE_DURATION", dur(&fc.Cache.Duration)},
+		{"MCACCUTILS_CACHE_NOT_FOUND_DURATION", dur(&fc.Cache.NotFoundDuration)},
+		{"MCACCUTILS_RATE_LIMIT_REQUESTS", num(&fc.RateLimit.Requests)},
+		{"MCACCUTILS_RATE_LIMIT_PERIOD", dur(&fc.RateLimit.Period)},
+		{"MCACCUTILS_RATE_LIMIT_ADAPTIVE", boolean(&fc.RateLimit.Adaptive)},
+		{"MCACCUTILS_RATE_LIMIT_MAX", num(&fc.RateLimit.Max)},
+		{"MCACCUTILS_HISTORY", str(&fc.History)},
+		{"MCACCUTILS_KEYS", str(&fc.Keys)},
+		{"MCACCUTILS_CONSUMER_RATE_LIMIT", num(&fc.ConsumerRateLimit)},
+		{"MCACCUTILS_CHECK_UPSTREAM", boolean(&fc.CheckUpstream)},
+		{"MCACCUTILS_RENDER_ENABLED", boolean(&fc.Render.Enabled)},
+		{"MCACCUTILS_RENDER_CACHE", str(&fc.Render.Cache)},
+	}
+	for _, o := range overrides {
+		v, ok := lookup(o.key)
+		if !ok {
+			continue
+		}
+		if err := o.set(v); err != nil {
+			return fmt.Errorf("mcaccutils: bad %s: %w", o.key, err)
+		}
+	}
+	return nil
+}
+
+// clientConfig returns the client configuration described by fc.
+func (fc *fileConfig) clientConfig() (cfg mcaccutils.Config, err error) {
+	switch p := strings.ToLower(fc.Provider); {
+	case p == "" || p == "mojang":
+		cfg = mcaccutils.DefaultConfig()
+	case p == "elyby":
+		cfg = mcaccutils.AuthlibInjectorConfig(providers.ElyByRoot)
+	case p == "littleskin":
+		cfg = mcaccutils.AuthlibInjectorConfig(providers.LittleSkinRoot)
+	case strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://"):
+		cfg = mcaccutils.AuthlibInjectorConfig(fc.Provider)
+	default:
+		return cfg, fmt.Errorf("mcaccutils: unknown provider %q", fc.Provider)
+	}
+	switch strings.ToLower(fc.LookupService) {
+	case "", "api":
+	case "services":
+		cfg.LookupService = mcaccutils.LookupMinecraftServices
+	case "fallback":
+		cfg.LookupService = mcaccutils.LookupFallback
+	case "failover":
+		cfg.LookupService = mcaccutils.LookupFailover
+	default:
+		return cfg, fmt.Errorf("mcaccutils: unknown lookup service %q", fc.LookupService)
+	}
+	if fc.Cache.Duration != 0 {
+		cfg.CacheDuration = fc.Cache.Duration
+	}
+	if fc.Cache.NotFoundDuration != 0 {
+		cfg.NotFoundCacheDuration = fc.Cache.NotFoundDuration
+	}
+	if fc.RateLimit.Requests != 0 {
+		cfg.RateLimit = fc.RateLimit.Requests
+	}
+	if fc.RateLimit.Period != 0 {
+		cfg.RateLimitPeriod = fc.RateLimit.Period
+	}
+	if fc.RateLimit.Adaptive {
+		cfg.AdaptiveRateLimit = true
+	}
+	if fc.RateLimit.Max != 0 {
+		cfg.MaxRateLimit = fc.RateLimit.Max
+	}
+	return cfg, nil
+}
+
+// openStore returns the external cache selected by fc, or nil for the
+// memory backend.
+func (fc *fileConfig) openStore() (mcaccutils.Store, error) {
+	switch strings.ToLower(fc.Cache.Backend) {
+	case "", "memory":
+		return nil, nil
+	case "sqlite":
+		if fc.Cache.Path == "" {
+			return nil, fmt.Errorf("mcaccutils: the sqlite cache backend needs a path")
+		}
+		db, err := openSQLite(fc.Cache.Path)
+		if err != nil {
+			return nil, err
+		}
+		return mcaccutils.NewSQLStore(db, mcaccutils.SQLite), nil
+	}
+	return nil, fmt.Errorf("mcaccutils: unknown cache backend %q", fc.Cache.Backend)
+}
+
+// openSQLite opens the SQLite database at path and brings its schema up to
+// date.
+func openSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := mcaccutils.MigrateSQL(db, mcaccutils.SQLite); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
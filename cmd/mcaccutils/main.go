@@ -0,0 +1,306 @@
+// Command mcaccutils looks up Minecraft accounts from the command line.
+//
+// Usage:
+//
+//	mcaccutils [flags] command [arguments]
+//
+// The commands are:
+//
+//	uuid NAME       print the UUID and case corrected name of a player
+//	name UUID       print the current name of a player
+//	names UUID      print the name history of a player
+//	history QUERY   print the recorded lookups of a name or UUID
+//	owners NAME     print the players recorded with a name
+//	export FILE     copy the recorded lookups to FILE, as CSV if its name
+//	                ends in .csv or is -, for standard output, and as a
+//	                SQLite database otherwise
+//	whitelist FILE  print an online mode whitelist.json for the offline mode
+//	                whitelist or list of names in FILE, reporting the names
+//	                which no longer exist
+//	sync FILE       keep the names in the whitelist.json FILE up to date as
+//	                players rename, until interrupted
+//	bans FILE       update the names in the banned-players.json FILE to
+//	                those the players go by now
+//	serve [ADDR]    serve a caching mirror of the Mojang API on ADDR
+//
+// The flags are:
+//
+//	-config FILE    read settings from the YAML file FILE
+//	-history FILE   record lookups in, and read history from, the SQLite
+//	                database FILE
+//	-keys FILE      require the API keys listed in the JSON file FILE to use
+//	                the mirror served by serve
+//	-rate N         allow each consumer of the mirror N requests a minute
+//
+// The serve command also accepts API keys from the MCACCUTILS_API_KEYS
+// environment variable, in the form described by service.ParseKeys.
+//
+// The config file sets up the client and the mirror:
+//
+//	listen: :8080
+//	provider: mojang         # mojang, elyby, littleskin or an authlib-injector URL
+//	lookupService: failover  # api, services, fallback or failover
+//	cache:
+//	  backend: sqlite        # memory or sqlite
+//	  path: cache.db
+//	  duration: 12h
+//	  notFoundDuration: 10m
+//	rateLimit:
+//	  requests: 600
+//	  period: 10m
+//	  adaptive: true
+//	  max: 1200
+//	history: history.db
+//	keys: keys.json
+//	consumerRateLimit: 60
+//	checkUpstream: true
+//	render:
+//	  enabled: true          # serve /render/{face,head,body}/{query}
+//	  cache: skins
+//
+// Every setting can be overridden by an environment variable, named after
+// its path in upper case with MCACCUTILS_ in front, such as
+// MCACCUTILS_CACHE_DURATION and MCACCUTILS_RATE_LIMIT_PERIOD, and the flags
+// override both.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/bearbin/go-mcaccutils/service"
+	"github.com/bearbin/go-mcaccutils/skin"
+	"github.com/bearbin/go-mcaccutils/whitelist"
+	_ "modernc.org/sqlite"
+)
+
+var (
+	configFile  = flag.String("config", "", "read settings from this YAML `file`")
+	historyFile = flag.String("history", "", "record lookups in, and read history from, this SQLite `file`")
+	keysFile    = flag.String("keys", "", "require the API keys listed in this JSON `file` to use the mirror")
+	consumerMax = flag.Int("rate", 0, "allow each consumer of the mirror `n` requests a minute")
+)
+
+// syncInterval is how often the sync command checks the whole whitelist.
+const syncInterval = 10 * time.Minute
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: mcaccutils [flags] command [arguments]\n\n")
+	fmt.Fprintf(os.Stderr, "commands:\n")
+	fmt.Fprintf(os.Stderr, "  uuid NAME       print the UUID and case corrected name of a player\n")
+	fmt.Fprintf(os.Stderr, "  name UUID       print the current name of a player\n")
+	fmt.Fprintf(os.Stderr, "  names UUID      print the name history of a player\n")
+	fmt.Fprintf(os.Stderr, "  history QUERY   print the recorded lookups of a name or UUID\n")
+	fmt.Fprintf(os.Stderr, "  owners NAME     print the players recorded with a name\n")
+	fmt.Fprintf(os.Stderr, "  export FILE     copy the recorded lookups to a CSV file or SQLite database\n")
+	fmt.Fprintf(os.Stderr, "  whitelist FILE  convert an offline mode whitelist to online mode\n")
+	fmt.Fprintf(os.Stderr, "  sync FILE       keep the names in a whitelist.json file up to date\n")
+	fmt.Fprintf(os.Stderr, "  bans FILE       update the names in a banned-players.json file\n")
+	fmt.Fprintf(os.Stderr, "  serve [ADDR]    serve a caching mirror of the Mojang API on ADDR\n\n")
+	fmt.Fprintf(os.Stderr, "flags:\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 2 && !(flag.NArg() == 1 && flag.Arg(0) == "serve") {
+		usage()
+	}
+	fc, err := loadConfig(*configFile)
+	if err != nil {
+		fatal(err)
+	}
+	// Flags take precedence over the config file and the environment.
+	if *historyFile != "" {
+		fc.History = *historyFile
+	}
+	if *keysFile != "" {
+		fc.Keys = *keysFile
+	}
+	if *consumerMax != 0 {
+		fc.ConsumerRateLimit = *consumerMax
+	}
+	cfg, err := fc.clientConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if cfg.Store, err = fc.openStore(); err != nil {
+		fatal(err)
+	}
+	var history *mcaccutils.SQLHistory
+	if fc.History != "" {
+		db, err := openSQLite(fc.History)
+		if err != nil {
+			fatal(err)
+		}
+		defer db.Close()
+		history = mcaccutils.NewSQLHistory(db, mcaccutils.SQLite)
+		cfg.History = history
+	}
+	c := mcaccutils.NewClient(cfg)
+
+	arg := flag.Arg(1)
+	switch flag.Arg(0) {
+	case "uuid":
+		uuid, name, err := c.GetUUID(arg)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println(uuid, name)
+	case "name":
+		name, err := c.GetName(arg)
+		if err != nil {
+			fatal(err)
+		}
+		fmt.Println(name)
+	case "names":
+		names, err := c.GetNameHistory(arg)
+		if err != nil {
+			fatal(err)
+		}
+		for _, n := range names {
+			if n.ChangedAt.IsZero() {
+				fmt.Printf("%-20s %s\n", "-", n.Name)
+				continue
+			}
+			fmt.Printf("%-20s %s\n", n.ChangedAt.Format(time.RFC3339), n.Name)
+		}
+	case "history":
+		if history == nil {
+			fatal(fmt.Errorf("mcaccutils: history needs the -history flag"))
+		}
+		lookups, err := history.Lookups(arg)
+		if err != nil {
+			fatal(err)
+		}
+		for _, l := range lookups {
+			fmt.Printf("%-20s %s %s\n", l.Time.Format(time.RFC3339), l.UUID, l.Name)
+		}
+	case "owners":
+		if history == nil {
+			fatal(fmt.Errorf("mcaccutils: owners needs the -history flag"))
+		}
+		owners, err := c.PreviousOwners(context.Background(), arg)
+		if err != nil {
+			fatal(err)
+		}
+		for _, o := range owners {
+			fmt.Printf("%-20s %-20s %s\n", o.FirstSeen.Format(time.RFC3339), o.LastSeen.Format(time.RFC3339), o.UUID)
+		}
+	case "export":
+		if history == nil {
+			fatal(fmt.Errorf("mcaccutils: export needs the -history flag"))
+		}
+		if err := export(history, arg); err != nil {
+			fatal(err)
+		}
+	case "whitelist":
+		if err := convertWhitelist(c, arg); err != nil {
+			fatal(err)
+		}
+	case "sync":
+		c.StartRefresher()
+		fatal(whitelist.Sync(context.Background(), arg, c, syncInterval, func(r whitelist.Rename) {
+			fmt.Printf("%s %s -> %s\n", r.UUID, r.From, r.To)
+		}))
+	case "bans":
+		renames, err := whitelist.UpdateBans(context.Background(), arg, c)
+		for _, r := range renames {
+			fmt.Printf("%s %s -> %s\n", r.UUID, r.From, r.To)
+		}
+		if err != nil {
+			fatal(err)
+		}
+	case "serve":
+		if arg == "" {
+			arg = fc.Listen
+		}
+		if arg == "" {
+			fatal(fmt.Errorf("mcaccutils: serve needs an address"))
+		}
+		h := &service.Handler{
+			Client:            c,
+			ConsumerRateLimit: fc.ConsumerRateLimit,
+			CheckUpstream:     fc.CheckUpstream,
+			Renders:           fc.Render.Enabled,
+		}
+		if fc.Render.Cache != "" {
+			h.SkinCache = &skin.DiskCache{Dir: fc.Render.Cache}
+		}
+		if fc.Keys != "" {
+			keys, err := service.LoadKeys(fc.Keys)
+			if err != nil {
+				fatal(err)
+			}
+			h.Keys = keys
+		}
+		keys, err := service.ParseKeys(os.Getenv("MCACCUTILS_API_KEYS"))
+		if err != nil {
+			fatal(err)
+		}
+		h.Keys = append(h.Keys, keys...)
+		// Keep hot players fresh, which also finds the renames sent to
+		// /events.
+		c.StartRefresher()
+		fatal(http.ListenAndServe(arg, h))
+	default:
+		usage()
+	}
+}
+
+// export copies the lookups in history to the file at path, as CSV if its
+// name ends in .csv or is "-", for standard output, and as a SQLite database
+// otherwise.
+func export(history *mcaccutils.SQLHistory, path string) error {
+	if path == "-" {
+		return history.ExportCSV(os.Stdout)
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".csv") {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := history.ExportCSV(f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	db, err := openSQLite(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return history.CopyTo(mcaccutils.NewSQLHistory(db, mcaccutils.SQLite))
+}
+
+// convertWhitelist writes an online mode whitelist for the whitelist or list
+// of names in the file at path to standard output, and the names which no
+// longer exist to standard error.
+func convertWhitelist(c *mcaccutils.Client, path string) error {
+	entries, err := whitelist.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	online, missing, err := whitelist.Convert(context.Background(), c, entries)
+	for _, name := range missing {
+		fmt.Fprintf(os.Stderr, "%s: no such player\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	return whitelist.Write(os.Stdout, online)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
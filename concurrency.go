@@ -0,0 +1,71 @@
+package mcaccutils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// endpointOf returns the endpoint a request is for: its host and path, with
+// the query of the lookup it was made for replaced by a placeholder, so all
+// lookups at the same endpoint share a name.
+func endpointOf(req *http.Request) string {
+	path := req.URL.Path
+	if q := queryFrom(req.Context()); q != "" {
+		path = strings.Replace(path, q, "{query}", -1)
+	}
+	return req.URL.Host + path
+}
+
+// endpointLimiter limits the number of requests in flight to each endpoint. A
+// nil *endpointLimiter does not limit anything.
+type endpointLimiter struct {
+	max  int
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// newEndpointLimiter returns an endpointLimiter allowing max requests to each
+// endpoint at once, or nil if max is not positive.
+func newEndpointLimiter(max int) *endpointLimiter {
+	if max <= 0 {
+		return nil
+	}
+	return &endpointLimiter{max: max, sems: make(map[string]chan struct{})}
+}
+
+// acquire waits until a request to endpoint may be sent, and returns the
+// function which must be called once it has finished.
+func (l *endpointLimiter) acquire(ctx context.Context, endpoint string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	sem, ok := l.sems[endpoint]
+	if !ok {
+		sem = make(chan struct{}, l.max)
+		l.sems[endpoint] = sem
+	}
+	l.mu.Unlock()
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-sem }) }, nil
+}
+
+// releaseBody calls release when the body it wraps is closed, so a request
+// counts as in flight until its response has been read.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b releaseBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
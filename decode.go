@@ -0,0 +1,64 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// snippetRadius is the number of bytes either side of a decoding error
+// included in a DecodeError.
+const snippetRadius = 32
+
+// A DecodeError is returned when a response from the API cannot be decoded,
+// whether because it is not valid JSON, because a value has the wrong type,
+// or, with Config.StrictJSON, because it has a field the package does not
+// know about.
+type DecodeError struct {
+	// Endpoint is the URL the response came from.
+	Endpoint string
+	// Snippet is the part of the response around where decoding failed.
+	Snippet string
+	// Err is the error returned by encoding/json.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("mcaccutils: decoding response from %s near %q: %v", e.Endpoint, e.Snippet, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeJSON decodes the body of a response from endpoint into v, rejecting
+// unknown fields if the client is configured for strict decoding. Errors are
+// returned as a *DecodeError.
+func (c *Client) decodeJSON(endpoint string, body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if c.Config().StrictJSON {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+	off := int(dec.InputOffset())
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		off = int(e.Offset)
+	case *json.UnmarshalTypeError:
+		off = int(e.Offset)
+	}
+	start, end := off-snippetRadius, off+snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	if end > len(body) {
+		end = len(body)
+	}
+	if start > end {
+		start = end
+	}
+	return &DecodeError{Endpoint: endpoint, Snippet: string(body[start:end]), Err: err}
+}
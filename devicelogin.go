@@ -0,0 +1,336 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The base URLs of the services used to log in with a Microsoft account,
+// used when the matching Config fields are empty.
+const (
+	MicrosoftLoginURL = "https://login.microsoftonline.com/consumers"
+	XboxUserAuthURL   = "https://user.auth.xboxlive.com"
+	XSTSAuthURL       = "https://xsts.auth.xboxlive.com"
+)
+
+const msDeviceCodeType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// A DeviceCode is the code a user enters to approve a device code login.
+type DeviceCode struct {
+	// UserCode is the code the user must enter at VerificationURI.
+	UserCode string
+	// VerificationURI is the page where the user enters the code.
+	VerificationURI string
+	// Message is a ready made instruction for the user, in their language.
+	Message string
+	// ExpiresAt is when the code stops working.
+	ExpiresAt time.Time
+}
+
+// A DevicePrompt shows the progress of a device code login to the user, in
+// whatever way suits the program: printed to a terminal, in a dialog, or sent
+// in a chat message by a bot.
+type DevicePrompt interface {
+	// ShowCode is called once the code has been issued, and should ask the
+	// user to enter it.
+	ShowCode(code DeviceCode)
+	// Waiting is called each time the server is polled and the user has not
+	// finished yet, with the time left before the code expires.
+	Waiting(remaining time.Duration)
+	// Done is called when the login finishes, with the error if it failed.
+	Done(err error)
+}
+
+// writerPrompt is the DevicePrompt returned by WriterPrompt.
+type writerPrompt struct {
+	w io.Writer
+}
+
+// WriterPrompt returns a DevicePrompt which writes the instructions for the
+// user and the result of the login to w, such as os.Stderr in a command line
+// program.
+func WriterPrompt(w io.Writer) DevicePrompt {
+	return writerPrompt{w}
+}
+
+func (p writerPrompt) ShowCode(code DeviceCode) {
+	if code.Message != "" {
+		fmt.Fprintln(p.w, code.Message)
+		return
+	}
+	fmt.Fprintf(p.w, "To sign in, open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+}
+
+func (p writerPrompt) Waiting(remaining time.Duration) {}
+
+func (p writerPrompt) Done(err error) {
+	if err != nil {
+		fmt.Fprintln(p.w, "Sign in failed:", err)
+		return
+	}
+	fmt.Fprintln(p.w, "Signed in.")
+}
+
+// do sends req through the client, and decodes a successful JSON response
+// into resp. Error responses are returned as an *AuthError.
+func (c *Client) do(req *http.Request, resp interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	r, err := c.doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", req.URL, err)
+	}
+	defer r.Body.Close()
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", req.URL, err)
+	}
+	if r.StatusCode != http.StatusOK {
+		var e struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Message     string `json:"errorMessage"`
+			XErr        int64  `json:"XErr"`
+		}
+		json.Unmarshal(body, &e)
+		authErr := newAuthError(r)
+		authErr.Type, authErr.Message = e.Error, e.Description
+		if e.Message != "" {
+			authErr.Message = e.Message
+		}
+		if e.XErr != 0 {
+			authErr.Type = fmt.Sprintf("XErr %d", e.XErr)
+		}
+		return authErr
+	}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", req.URL, err)
+	}
+	return nil
+}
+
+// postForm posts a form to endpoint, decoding the response into resp.
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values, resp interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, resp)
+}
+
+// postJSON posts v as JSON to endpoint, decoding the response into resp.
+func (c *Client) postJSON(ctx context.Context, endpoint string, v, resp interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, resp)
+}
+
+type msToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LoginWithDeviceCode logs in to a Microsoft account with the device code
+// flow, and returns a session for its Minecraft profile. clientID is the ID
+// of the Azure application of the program, which must be allowed to use the
+// Minecraft APIs. The progress of the login is reported to prompt.
+//
+// The session holds the Microsoft refresh token, which RefreshMicrosoft uses
+// to log in again without the user. It is as sensitive as a password, so
+// sessions should be kept in a secure TokenStore.
+//
+// The login requests do not wait for or count towards the rate limit, which
+// only covers player lookups.
+func (c *Client) LoginWithDeviceCode(ctx context.Context, clientID string, prompt DevicePrompt) (s *Session, err error) {
+	defer func() { prompt.Done(err) }()
+	ctx = context.WithValue(ctx, unlimitedKey{}, true)
+	var code struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		Message         string `json:"message"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+	}
+	err = c.postForm(ctx, c.microsoftLoginURL()+"/oauth2/v2.0/devicecode", url.Values{
+		"client_id": {clientID},
+		"scope":     {"XboxLive.signin offline_access"},
+	}, &code)
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	prompt.ShowCode(DeviceCode{
+		UserCode:        code.UserCode,
+		VerificationURI: code.VerificationURI,
+		Message:         code.Message,
+		ExpiresAt:       expires,
+	})
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, canceled(ctx)
+		case <-time.After(interval):
+		}
+		var token msToken
+		err := c.postForm(ctx, c.microsoftLoginURL()+"/oauth2/v2.0/token", url.Values{
+			"client_id":   {clientID},
+			"grant_type":  {msDeviceCodeType},
+			"device_code": {code.DeviceCode},
+		}, &token)
+		var authErr *AuthError
+		if errors.As(err, &authErr) {
+			switch authErr.Type {
+			case "authorization_pending":
+				prompt.Waiting(time.Until(expires))
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				prompt.Waiting(time.Until(expires))
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return c.loginWithMicrosoft(ctx, token)
+	}
+}
+
+// LoginWithDeviceCode calls LoginWithDeviceCode on the default client.
+func LoginWithDeviceCode(ctx context.Context, clientID string, prompt DevicePrompt) (*Session, error) {
+	return defaultClient.LoginWithDeviceCode(ctx, clientID, prompt)
+}
+
+// RefreshMicrosoft uses the Microsoft refresh token of s, a session from
+// LoginWithDeviceCode, to log in again. Minecraft access tokens only last a
+// day, so long running programs need to do this regularly. Like
+// LoginWithDeviceCode, it does not count towards the rate limit, which only
+// covers player lookups.
+func (c *Client) RefreshMicrosoft(ctx context.Context, clientID string, s *Session) (*Session, error) {
+	ctx = context.WithValue(ctx, unlimitedKey{}, true)
+	var token msToken
+	err := c.postForm(ctx, c.microsoftLoginURL()+"/oauth2/v2.0/token", url.Values{
+		"client_id":     {clientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.RefreshToken},
+		"scope":         {"XboxLive.signin offline_access"},
+	}, &token)
+	if err != nil {
+		return nil, err
+	}
+	return c.loginWithMicrosoft(ctx, token)
+}
+
+// RefreshMicrosoft calls RefreshMicrosoft on the default client.
+func RefreshMicrosoft(ctx context.Context, clientID string, s *Session) (*Session, error) {
+	return defaultClient.RefreshMicrosoft(ctx, clientID, s)
+}
+
+// loginWithMicrosoft exchanges a Microsoft access token for a Minecraft one,
+// through Xbox Live.
+func (c *Client) loginWithMicrosoft(ctx context.Context, token msToken) (*Session, error) {
+	var xbl, xsts struct {
+		Token         string `json:"Token"`
+		DisplayClaims struct {
+			XUI []struct {
+				UHS string `json:"uhs"`
+			} `json:"xui"`
+		} `json:"DisplayClaims"`
+	}
+	err := c.postJSON(ctx, c.xboxUserAuthURL()+"/user/authenticate", map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"AuthMethod": "RPS",
+			"SiteName":   "user.auth.xboxlive.com",
+			"RpsTicket":  "d=" + token.AccessToken,
+		},
+		"RelyingParty": "http://auth.xboxlive.com",
+		"TokenType":    "JWT",
+	}, &xbl)
+	if err != nil {
+		return nil, err
+	}
+	err = c.postJSON(ctx, c.xstsAuthURL()+"/xsts/authorize", map[string]interface{}{
+		"Properties": map[string]interface{}{
+			"SandboxId":  "RETAIL",
+			"UserTokens": []string{xbl.Token},
+		},
+		"RelyingParty": "rp://api.minecraftservices.com/",
+		"TokenType":    "JWT",
+	}, &xsts)
+	if err != nil {
+		return nil, err
+	}
+	if len(xsts.DisplayClaims.XUI) == 0 {
+		return nil, errors.New("mcaccutils: Xbox Live returned no user hash")
+	}
+	var mc struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = c.postJSON(ctx, c.servicesURL()+"/authentication/login_with_xbox", map[string]string{
+		"identityToken": "XBL3.0 x=" + xsts.DisplayClaims.XUI[0].UHS + ";" + xsts.Token,
+	}, &mc)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.servicesURL()+"/minecraft/profile", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+mc.AccessToken)
+	profile := &Profile{}
+	if err := c.do(req, profile); err != nil {
+		return nil, err
+	}
+	return &Session{
+		AccessToken:       mc.AccessToken,
+		RefreshToken:      token.RefreshToken,
+		SelectedProfile:   &Profile{UUID: profile.UUID, Name: profile.Name},
+		AvailableProfiles: []Profile{{UUID: profile.UUID, Name: profile.Name}},
+	}, nil
+}
+
+// microsoftLoginURL returns the base URL of the Microsoft identity platform.
+func (c *Client) microsoftLoginURL() string {
+	if u := c.Config().MicrosoftLoginURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return MicrosoftLoginURL
+}
+
+// xboxUserAuthURL returns the base URL of the Xbox Live user authentication
+// service.
+func (c *Client) xboxUserAuthURL() string {
+	if u := c.Config().XboxUserAuthURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return XboxUserAuthURL
+}
+
+// xstsAuthURL returns the base URL of the Xbox Live security token service.
+func (c *Client) xstsAuthURL() string {
+	if u := c.Config().XSTSAuthURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return XSTSAuthURL
+}
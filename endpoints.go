@@ -0,0 +1,137 @@
+package mcaccutils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrEndpointRetired is matched by the errors returned for lookups which need
+// an endpoint the API no longer serves.
+var ErrEndpointRetired = errors.New("mcaccutils: endpoint retired")
+
+// A RetiredError is returned for a lookup which needs an endpoint the API no
+// longer serves. It matches ErrEndpointRetired.
+type RetiredError struct {
+	// Feature is the feature the endpoint provided.
+	Feature Feature
+	// Since is when the endpoint was retired, or the zero time if the client
+	// only found out when the API stopped answering it.
+	Since time.Time
+}
+
+func (e *RetiredError) Error() string {
+	if e.Since.IsZero() {
+		return fmt.Sprintf("mcaccutils: %s endpoint retired", e.Feature)
+	}
+	return fmt.Sprintf("mcaccutils: %s endpoint retired on %s", e.Feature, e.Since.Format("2006-01-02"))
+}
+
+func (e *RetiredError) Is(target error) bool {
+	return target == ErrEndpointRetired
+}
+
+// A Feature is a kind of lookup which needs a particular API endpoint.
+type Feature int
+
+const (
+	// FeatureNameHistory is needed by GetNameHistory and the functions built
+	// on it.
+	FeatureNameHistory Feature = iota
+	// FeatureUUIDLookup is needed by GetUUID and GetUUIDs.
+	FeatureUUIDLookup
+	// FeatureProfile is needed by GetProfile, GetName and the functions
+	// built on them.
+	FeatureProfile
+)
+
+func (f Feature) String() string {
+	if spec, ok := endpointRegistry[f]; ok {
+		return spec.name
+	}
+	return fmt.Sprintf("Feature(%d)", int(f))
+}
+
+// endpointSpec describes the endpoint providing a feature.
+type endpointSpec struct {
+	// name describes the endpoint in errors.
+	name string
+	// retired maps the base URLs of the services which no longer serve the
+	// endpoint to when they stopped.
+	retired map[string]time.Time
+}
+
+// endpointRegistry holds the endpoint providing each feature.
+var endpointRegistry = map[Feature]endpointSpec{
+	FeatureNameHistory: {
+		name: "name history",
+		retired: map[string]time.Time{
+			MojangAPIURL: time.Date(2022, time.September, 13, 0, 0, 0, 0, time.UTC),
+		},
+	},
+	FeatureUUIDLookup: {name: "profile lookup"},
+	FeatureProfile:    {name: "session profile"},
+}
+
+// featureBase returns the base URL of the service the client uses first for
+// f.
+func (c *Client) featureBase(f Feature) string {
+	switch {
+	case f == FeatureProfile:
+		return c.sessionServerURL()
+	case f == FeatureUUIDLookup && c.usingServices():
+		return c.servicesURL()
+	}
+	return c.apiURL()
+}
+
+// Supports reports whether the client expects the API to serve the endpoint
+// needed by f. It turns false once the endpoint is known to be retired,
+// either in advance or because the API answered a request with 410 Gone.
+// With LookupFallback, it only describes the account API.
+func (c *Client) Supports(f Feature) bool {
+	return c.checkEndpoint(f, c.featureBase(f)) == nil
+}
+
+// Supports calls Supports on the default client.
+func Supports(f Feature) bool {
+	return defaultClient.Supports(f)
+}
+
+// retiredEndpoint identifies the endpoint providing a feature at the service
+// with a base URL.
+type retiredEndpoint struct {
+	feature Feature
+	base    string
+}
+
+// checkEndpoint returns a *RetiredError if the endpoint needed by f at the
+// service with the base URL base is retired.
+func (c *Client) checkEndpoint(f Feature, base string) error {
+	if since, ok := endpointRegistry[f].retired[base]; ok {
+		return &RetiredError{Feature: f, Since: since}
+	}
+	c.retiredMu.RLock()
+	defer c.retiredMu.RUnlock()
+	if c.retired[retiredEndpoint{f, base}] {
+		return &RetiredError{Feature: f}
+	}
+	return nil
+}
+
+// checkRetired records that the endpoint needed by f at the service with the
+// base URL base is retired and returns a *RetiredError if resp says so, so
+// later lookups fail without a request.
+func (c *Client) checkRetired(f Feature, base string, resp *http.Response) error {
+	if resp.StatusCode != http.StatusGone {
+		return nil
+	}
+	c.retiredMu.Lock()
+	defer c.retiredMu.Unlock()
+	if c.retired == nil {
+		c.retired = make(map[retiredEndpoint]bool)
+	}
+	c.retired[retiredEndpoint{f, base}] = true
+	return &RetiredError{Feature: f}
+}
@@ -0,0 +1,142 @@
+package mcaccutils
+
+import (
+	"errors"
+	"time"
+)
+
+// An EventType is the kind of an Event.
+type EventType int
+
+const (
+	// EventResolved is published when a lookup finds a player through the
+	// API.
+	EventResolved EventType = iota
+	// EventNotFound is published when the API reports that no player matches
+	// a lookup.
+	EventNotFound
+	// EventRateLimited is published when the API rejects a request with 429
+	// Too Many Requests.
+	EventRateLimited
+	// EventRefreshed is published when the background refresher fetches a
+	// hot entry again.
+	EventRefreshed
+	// EventSkinChanged is published when a player is found to have changed
+	// their skin.
+	EventSkinChanged
+	// EventNameChanged is published when a player is found to have changed
+	// their name.
+	EventNameChanged
+	// EventNameAvailable is published when a name watched with WatchNames
+	// becomes available.
+	EventNameAvailable
+	// EventFailover is published when LookupFailover stops sending lookups
+	// to the account API, whose URL is the Query of the event.
+	EventFailover
+	// EventFailback is published when LookupFailover finds the account API
+	// has recovered, and sends lookups to it again.
+	EventFailback
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventResolved:
+		return "resolved"
+	case EventNotFound:
+		return "not_found"
+	case EventRateLimited:
+		return "rate_limited"
+	case EventRefreshed:
+		return "refreshed"
+	case EventSkinChanged:
+		return "skin_changed"
+	case EventNameChanged:
+		return "name_changed"
+	case EventNameAvailable:
+		return "name_available"
+	case EventFailover:
+		return "failover"
+	case EventFailback:
+		return "failback"
+	}
+	return "unknown"
+}
+
+// An Event is something that happened in a Client which other components may
+// want to react to.
+type Event struct {
+	Type EventType
+	// Query is the username or UUID being looked up, if any.
+	Query string
+	// UUID and Name identify the player, for EventResolved, EventRefreshed
+	// and EventNameChanged. Name is the name which was released for
+	// EventNameAvailable.
+	UUID string
+	Name string
+	// OldName is the name the player had before, for EventNameChanged.
+	OldName string
+	// SkinHash is the hash of the new skin, for EventSkinChanged.
+	SkinHash string
+	// Time is when the event happened.
+	Time time.Time
+}
+
+// Subscribe registers f to be called with every event published by the
+// client. Events are delivered synchronously from the goroutine which caused
+// them, so f should return quickly, handing off any slow work.
+//
+// The returned function removes the subscription.
+func (c *Client) Subscribe(f func(e Event)) (unsubscribe func()) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	if c.subs == nil {
+		c.subs = make(map[int]func(e Event))
+	}
+	id := c.nextSub
+	c.nextSub++
+	c.subs[id] = f
+	return func() {
+		c.subMu.Lock()
+		defer c.subMu.Unlock()
+		delete(c.subs, id)
+	}
+}
+
+// Subscribe calls Subscribe on the default client.
+func Subscribe(f func(e Event)) (unsubscribe func()) {
+	return defaultClient.Subscribe(f)
+}
+
+// publish delivers e to every subscriber of the client.
+func (c *Client) publish(e Event) {
+	// Copy the subscribers, so they may subscribe and unsubscribe while
+	// handling the event.
+	c.subMu.RLock()
+	subs := make([]func(e Event), 0, len(c.subs))
+	for _, f := range c.subs {
+		subs = append(subs, f)
+	}
+	c.subMu.RUnlock()
+	if len(subs) == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = c.now()
+	}
+	for _, f := range subs {
+		f(e)
+	}
+}
+
+// publishLookup publishes the result of a lookup of query through the API.
+// Lookups which failed for a reason other than the player not existing
+// publish nothing.
+func (c *Client) publishLookup(query string, p *playerCacheData, err error) {
+	switch {
+	case err == nil:
+		c.publish(Event{Type: EventResolved, Query: query, UUID: p.UUID, Name: p.Username})
+	case errors.Is(err, ErrPlayerNotFound):
+		c.publish(Event{Type: EventNotFound, Query: query})
+	}
+}
@@ -0,0 +1,132 @@
+package mcaccutils
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Each calls f with every recorded lookup, including those which found no
+// player, oldest first. It stops at the first error returned by f, and
+// returns it.
+func (h *SQLHistory) Each(f func(l Lookup) error) error {
+	rows, err := h.db.Query(
+		"SELECT query, uuid, name, not_found, looked_up FROM mcaccutils_history ORDER BY looked_up",
+	)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: reading lookups: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			l  Lookup
+			ts int64
+		)
+		if err := rows.Scan(&l.Query, &l.UUID, &l.Name, &l.NotFound, &ts); err != nil {
+			return fmt.Errorf("mcaccutils: reading lookups: %w", err)
+		}
+		l.Time = time.Unix(ts, 0)
+		if err := f(l); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("mcaccutils: reading lookups: %w", err)
+	}
+	return nil
+}
+
+// ExportCSV writes every recorded lookup to w as CSV, oldest first, for
+// analysis in other tools. The columns are query, uuid, name, not_found and
+// time, with the time in RFC 3339 format.
+func (h *SQLHistory) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"query", "uuid", "name", "not_found", "time"})
+	err := h.Each(func(l Lookup) error {
+		return cw.Write([]string{l.Query, l.UUID, l.Name, strconv.FormatBool(l.NotFound), l.Time.UTC().Format(time.RFC3339)})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("mcaccutils: writing lookups: %w", err)
+	}
+	return nil
+}
+
+// CopyTo records every lookup in h to dst, oldest first. It can be used to
+// export the history to another database, such as a SQLite file opened with
+// NewSQLHistory, or to move it between databases.
+func (h *SQLHistory) CopyTo(dst History) error {
+	return h.Each(func(l Lookup) error {
+		if err := dst.Record(l); err != nil {
+			return fmt.Errorf("mcaccutils: copying lookups: %w", err)
+		}
+		return nil
+	})
+}
+
+// ExportCacheCSV writes the players in the memory cache of the client to w as
+// CSV, ordered by name. The columns are uuid, name and expires, with the
+// expiry time in RFC 3339 format. Failed lookups are left out.
+func (c *Client) ExportCacheCSV(w io.Writer) error {
+	type row struct {
+		p       *playerCacheData
+		expires time.Time
+	}
+	var rows []row
+	for k, item := range c.cache.Items() {
+		p := item.Value
+		// Every player is stored twice, so only look at the UUID entries.
+		if p.notFound || k != p.UUID {
+			continue
+		}
+		rows = append(rows, row{p, item.Expires})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].p.Username < rows[j].p.Username
+	})
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"uuid", "name", "expires"})
+	for _, r := range rows {
+		cw.Write([]string{r.p.UUID, r.p.Username, r.expires.UTC().Format(time.RFC3339)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("mcaccutils: writing cache: %w", err)
+	}
+	return nil
+}
+
+// ExportCacheCSV calls ExportCacheCSV on the default client.
+func ExportCacheCSV(w io.Writer) error {
+	return defaultClient.ExportCacheCSV(w)
+}
+
+// CachedPlayers returns the players in the memory cache of the client,
+// ordered by name, so they can be written to the files of other programs.
+// Failed lookups are left out.
+func (c *Client) CachedPlayers() []Match {
+	var players []Match
+	for k, item := range c.cache.Items() {
+		p := item.Value
+		// Every player is stored twice, so only look at the UUID entries.
+		if p.notFound || k != p.UUID {
+			continue
+		}
+		players = append(players, Match{UUID: p.UUID, Name: p.Username})
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+	return players
+}
+
+// CachedPlayers calls CachedPlayers on the default client.
+func CachedPlayers() []Match {
+	return defaultClient.CachedPlayers()
+}
@@ -0,0 +1,139 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// failover is the circuit breaker deciding where LookupFailover sends UUID
+// lookups. It is closed, sending them to the account API, until that fails
+// threshold times in a row. It then opens, sending them to the Minecraft
+// services API, except for one lookup every check interval, which tries the
+// account API again and closes it if that succeeds.
+type failover struct {
+	mu       sync.Mutex
+	failures int
+	down     bool
+	since    time.Time
+	// nextCheck is when the account API is next tried while down.
+	nextCheck time.Time
+}
+
+// isDown reports whether lookups are failed over.
+func (f *failover) isDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.down
+}
+
+// tryPrimary reports whether a lookup at time now should go to the account
+// API. While down, it returns true once per check interval.
+func (f *failover) tryPrimary(now time.Time, interval time.Duration) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.down {
+		return true
+	}
+	if now.Before(f.nextCheck) {
+		return false
+	}
+	f.nextCheck = now.Add(interval)
+	return true
+}
+
+// succeeded records a lookup the account API answered, reporting whether it
+// ended a failover.
+func (f *failover) succeeded() (recovered bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	recovered = f.down
+	f.failures, f.down = 0, false
+	return recovered
+}
+
+// failed records a lookup the account API failed at time now, reporting
+// whether it started a failover.
+func (f *failover) failed(now time.Time, threshold int, interval time.Duration) (tripped bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures++
+	if f.down || f.failures < threshold {
+		return false
+	}
+	f.down, f.since, f.nextCheck = true, now, now.Add(interval)
+	return true
+}
+
+// A FailoverState describes where a client using LookupFailover sends UUID
+// lookups.
+type FailoverState struct {
+	// FailedOver is set while lookups go to the Minecraft services API.
+	FailedOver bool
+	// Since is when the current failover started.
+	Since time.Time
+	// Failures is the number of lookups the account API has failed in a
+	// row.
+	Failures int
+}
+
+// Failover returns the state of the failover of UUID lookups, which only
+// changes with LookupFailover.
+func (c *Client) Failover() FailoverState {
+	c.failover.mu.Lock()
+	defer c.failover.mu.Unlock()
+	s := FailoverState{FailedOver: c.failover.down, Failures: c.failover.failures}
+	if s.FailedOver {
+		s.Since = c.failover.since
+	}
+	return s
+}
+
+// Failover calls Failover on the default client.
+func Failover() FailoverState {
+	return defaultClient.Failover()
+}
+
+// failoverThreshold returns the number of failures in a row which start a
+// failover.
+func (c *Client) failoverThreshold() int {
+	if n := c.Config().FailoverThreshold; n > 0 {
+		return n
+	}
+	return 3
+}
+
+// failoverCheckInterval returns how often the account API is tried while
+// failed over.
+func (c *Client) failoverCheckInterval() time.Duration {
+	if d := c.Config().FailoverCheckInterval; d > 0 {
+		return d
+	}
+	return time.Minute
+}
+
+// failoverUUIDs looks up the players with the given lowercased names for
+// LookupFailover. Lookups the account API fails are repeated with the
+// Minecraft services API straight away, so they still succeed while the
+// failover is starting.
+func (c *Client) failoverUUIDs(ctx context.Context, names []string) (players map[string]*playerCacheData, err error) {
+	interval := c.failoverCheckInterval()
+	if c.failover.tryPrimary(c.now(), interval) {
+		players, err = c.postUUIDs(ctx, c.apiURL(), "/profiles/minecraft", names)
+		if canceled(ctx) != nil {
+			return players, err
+		}
+		// A name with no player is an answer, not a failure.
+		if err == nil || errors.Is(err, ErrPlayerNotFound) {
+			if c.failover.succeeded() {
+				c.publish(Event{Type: EventFailback, Query: c.apiURL()})
+			}
+			return players, err
+		}
+		if c.failover.failed(c.now(), c.failoverThreshold(), interval) {
+			c.publish(Event{Type: EventFailover, Query: c.apiURL()})
+		}
+	}
+	return c.postUUIDs(ctx, c.servicesURL(), servicesBulkPath, names)
+}
@@ -0,0 +1,21 @@
+//go:build js && wasm
+
+package mcaccutils
+
+import (
+	"net/http"
+)
+
+// In the browser, requests are made with the Fetch API. They always cross
+// origins, whether they go to the Mojang API or to a CORS proxy, so ask for
+// CORS mode and leave out the page's credentials.
+func init() {
+	platformMiddleware = append(platformMiddleware, func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("js.fetch:mode", "cors")
+			req.Header.Set("js.fetch:credentials", "omit")
+			return next.Do(req)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+// Package forwarding decodes the player information forwarded by proxies
+// such as BungeeCord and Velocity to the servers behind them, for servers
+// written in Go which need the real address and identity of each player.
+package forwarding
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// BungeeData is the player information BungeeCord forwards in the server
+// address field of the handshake when ip_forward is enabled.
+type BungeeData struct {
+	// Host is the server address the player connected to.
+	Host string
+	// Address is the address of the player.
+	Address net.IP
+	// UUID is the undashed UUID of the player.
+	UUID string
+	// Properties holds the properties of the profile of the player, such as
+	// their textures, or nil if none were forwarded.
+	Properties []mcaccutils.ProfileProperty
+}
+
+// ParseBungee decodes the server address field of a handshake forwarded by
+// BungeeCord. It returns an error if the field carries no forwarded data,
+// which means the player connected directly, or the proxy is misconfigured.
+//
+// BungeeCord does not authenticate the data, so a server accepting it must
+// only be reachable through the proxy, or check a shared secret such as the
+// token added to the properties by BungeeGuard.
+func ParseBungee(serverAddress string) (*BungeeData, error) {
+	parts := strings.Split(serverAddress, "\x00")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("forwarding: handshake has no BungeeCord data")
+	}
+	d := &BungeeData{Host: parts[0], UUID: strings.ToLower(strings.Replace(parts[2], "-", "", -1))}
+	if d.Address = net.ParseIP(parts[1]); d.Address == nil {
+		return nil, fmt.Errorf("forwarding: invalid forwarded address %q", parts[1])
+	}
+	if _, err := mcaccutils.UUIDBytes(d.UUID); err != nil {
+		return nil, fmt.Errorf("forwarding: invalid forwarded UUID %q", parts[2])
+	}
+	// Anything else after the UUID, such as the marker added by Forge
+	// clients, is ignored.
+	if len(parts) > 3 && strings.HasPrefix(parts[3], "[") {
+		if err := json.Unmarshal([]byte(parts[3]), &d.Properties); err != nil {
+			return nil, fmt.Errorf("forwarding: decoding forwarded properties: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// Property returns the value of the named property, and whether it was
+// forwarded.
+func (d *BungeeData) Property(name string) (value string, ok bool) {
+	for _, p := range d.Properties {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,183 @@
+package forwarding
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// VelocityChannel is the channel of the login plugin messages Velocity uses
+// for modern forwarding.
+const VelocityChannel = "velocity:player_info"
+
+// The versions of the Velocity modern forwarding format.
+const (
+	VelocityDefault = 1
+	// VelocityWithKey and VelocityWithKeyV2 add the chat signing key of
+	// the player, used by Minecraft 1.19 to 1.19.2.
+	VelocityWithKey   = 2
+	VelocityWithKeyV2 = 3
+	// VelocityLazySession is used from Minecraft 1.19.3.
+	VelocityLazySession = 4
+)
+
+// ErrBadSignature is returned by ParseVelocity when the forwarded data is not
+// signed with the forwarding secret, which means it did not come from the
+// proxy, or the secrets do not match.
+var ErrBadSignature = errors.New("forwarding: bad Velocity signature")
+
+// VelocityData is the player information Velocity forwards in its reply to a
+// login plugin request on VelocityChannel.
+type VelocityData struct {
+	// Version is the version of the format the proxy used.
+	Version int
+	// Address is the address of the player.
+	Address net.IP
+	// UUID is the undashed UUID of the player.
+	UUID string
+	// Name is the username of the player.
+	Name string
+	// Properties holds the properties of the profile of the player, such as
+	// their textures.
+	Properties []mcaccutils.ProfileProperty
+}
+
+// Profile returns the forwarded profile of the player.
+func (d *VelocityData) Profile() *mcaccutils.Profile {
+	return &mcaccutils.Profile{UUID: d.UUID, Name: d.Name, Properties: d.Properties}
+}
+
+// VelocityRequest returns the data of the login plugin request a server sends
+// on VelocityChannel to ask for the player information, in a version of the
+// format up to max.
+func VelocityRequest(max int) []byte {
+	return []byte{byte(max)}
+}
+
+// ParseVelocity verifies the data of a login plugin response on
+// VelocityChannel with the forwarding secret shared with the proxy, and
+// decodes it. It returns ErrBadSignature if the data was not signed with
+// secret.
+func ParseVelocity(data, secret []byte) (*VelocityData, error) {
+	if len(data) < sha256.Size {
+		return nil, errors.New("forwarding: Velocity data too short")
+	}
+	sig, payload := data[:sha256.Size], data[sha256.Size:]
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrBadSignature
+	}
+	r := &velocityReader{r: bytes.NewReader(payload)}
+	d := &VelocityData{Version: int(r.varInt())}
+	if d.Version < VelocityDefault || d.Version > VelocityLazySession {
+		return nil, fmt.Errorf("forwarding: unsupported Velocity forwarding version %d", d.Version)
+	}
+	addr := r.string()
+	d.UUID = r.uuid()
+	d.Name = r.string()
+	n := r.varInt()
+	for i := int32(0); i < n && r.err == nil; i++ {
+		p := mcaccutils.ProfileProperty{Name: r.string(), Value: r.string()}
+		if r.bool() {
+			p.Signature = r.string()
+		}
+		d.Properties = append(d.Properties, p)
+	}
+	if d.Version == VelocityWithKey || d.Version == VelocityWithKeyV2 {
+		// The chat signing key is only needed to verify chat messages, so
+		// it is skipped.
+		r.long()
+		r.bytes()
+		r.bytes()
+		if d.Version == VelocityWithKeyV2 && r.bool() {
+			r.uuid()
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("forwarding: decoding Velocity data: %w", r.err)
+	}
+	if d.Address = net.ParseIP(addr); d.Address == nil {
+		return nil, fmt.Errorf("forwarding: invalid forwarded address %q", addr)
+	}
+	return d, nil
+}
+
+// maxFieldLength bounds the strings and byte arrays in forwarded data.
+const maxFieldLength = 1 << 16
+
+// velocityReader decodes the fields of Velocity forwarding data, keeping the
+// first error and returning zero values after it.
+type velocityReader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (v *velocityReader) varInt() int32 {
+	var u uint32
+	for i := 0; i < 5 && v.err == nil; i++ {
+		b, err := v.r.ReadByte()
+		if err != nil {
+			v.err = io.ErrUnexpectedEOF
+			return 0
+		}
+		u |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(u)
+		}
+	}
+	if v.err == nil {
+		v.err = errors.New("VarInt too long")
+	}
+	return 0
+}
+
+func (v *velocityReader) bytes() []byte {
+	n := v.varInt()
+	if v.err != nil {
+		return nil
+	}
+	if n < 0 || n > maxFieldLength || int(n) > v.r.Len() {
+		v.err = fmt.Errorf("field length %d out of range", n)
+		return nil
+	}
+	b := make([]byte, n)
+	v.r.Read(b)
+	return b
+}
+
+func (v *velocityReader) string() string {
+	return string(v.bytes())
+}
+
+func (v *velocityReader) read(n int) []byte {
+	if v.err != nil {
+		return make([]byte, n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(v.r, b); err != nil {
+		v.err = io.ErrUnexpectedEOF
+	}
+	return b
+}
+
+func (v *velocityReader) long() int64 {
+	return int64(binary.BigEndian.Uint64(v.read(8)))
+}
+
+func (v *velocityReader) bool() bool {
+	return v.read(1)[0] != 0
+}
+
+func (v *velocityReader) uuid() string {
+	var b [16]byte
+	copy(b[:], v.read(16))
+	return mcaccutils.UUIDFromBytes(b)
+}
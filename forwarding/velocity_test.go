@@ -0,0 +1,149 @@
+package forwarding
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+var (
+	testSecret = []byte("s3cret")
+	testUUID   = "069a79f444e94726a5befca90e38aaf5"
+)
+
+// velocityWriter encodes forwarding data the way Velocity does.
+type velocityWriter struct {
+	bytes.Buffer
+}
+
+func (w *velocityWriter) varInt(n int32) *velocityWriter {
+	u := uint32(n)
+	for u >= 0x80 {
+		w.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	w.WriteByte(byte(u))
+	return w
+}
+
+func (w *velocityWriter) string(s string) *velocityWriter {
+	w.varInt(int32(len(s)))
+	w.WriteString(s)
+	return w
+}
+
+func (w *velocityWriter) bool(b bool) *velocityWriter {
+	if b {
+		w.WriteByte(1)
+	} else {
+		w.WriteByte(0)
+	}
+	return w
+}
+
+func (w *velocityWriter) long(n int64) *velocityWriter {
+	binary.Write(w, binary.BigEndian, n)
+	return w
+}
+
+func (w *velocityWriter) uuid(uuid string) *velocityWriter {
+	b, _ := mcaccutils.UUIDBytes(uuid)
+	w.Write(b[:])
+	return w
+}
+
+// header writes the fields shared by every version, with one signed
+// textures property.
+func (w *velocityWriter) header(version int32, addr string) *velocityWriter {
+	return w.varInt(version).string(addr).uuid(testUUID).string("Notch").
+		varInt(1).string("textures").string("dGV4dHVyZXM=").bool(true).string("c2ln")
+}
+
+// sign prepends the signature of payload with secret.
+func sign(payload, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return append(mac.Sum(nil), payload...)
+}
+
+func TestParseVelocity(t *testing.T) {
+	withKey := func(version int32) *velocityWriter {
+		w := (&velocityWriter{}).header(version, "203.0.113.7")
+		return w.long(1700000000000).string("public key").string("key signature")
+	}
+	want := func(version int) *VelocityData {
+		return &VelocityData{
+			Version: version,
+			Address: net.ParseIP("203.0.113.7"),
+			UUID:    testUUID,
+			Name:    "Notch",
+			Properties: []mcaccutils.ProfileProperty{
+				{Name: "textures", Value: "dGV4dHVyZXM=", Signature: "c2ln"},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want *VelocityData
+		err  error
+	}{
+		{"v1", sign((&velocityWriter{}).header(1, "203.0.113.7").Bytes(), testSecret), want(1), nil},
+		{"v2", sign(withKey(2).Bytes(), testSecret), want(2), nil},
+		{"v3 with holder", sign(withKey(3).bool(true).uuid(testUUID).Bytes(), testSecret), want(3), nil},
+		{"v3 without holder", sign(withKey(3).bool(false).Bytes(), testSecret), want(3), nil},
+		{"v4", sign((&velocityWriter{}).header(4, "203.0.113.7").Bytes(), testSecret), want(4), nil},
+		{"tampered signature", func() []byte {
+			d := sign((&velocityWriter{}).header(1, "203.0.113.7").Bytes(), testSecret)
+			d[0] ^= 1
+			return d
+		}(), nil, ErrBadSignature},
+		{"tampered payload", func() []byte {
+			d := sign((&velocityWriter{}).header(1, "203.0.113.7").Bytes(), testSecret)
+			d[len(d)-1] ^= 1
+			return d
+		}(), nil, ErrBadSignature},
+		{"wrong secret", sign((&velocityWriter{}).header(1, "203.0.113.7").Bytes(), []byte("other")), nil, ErrBadSignature},
+		{"shorter than a signature", []byte("short"), nil, nil},
+		{"truncated", sign((&velocityWriter{}).header(1, "203.0.113.7").Bytes()[:20], testSecret), nil, nil},
+		{"truncated key", func() []byte {
+			b := withKey(2).Bytes()
+			return sign(b[:len(b)-3], testSecret)
+		}(), nil, nil},
+		{"missing holder", sign(withKey(3).bool(true).Bytes(), testSecret), nil, nil},
+		{"oversized string", sign((&velocityWriter{}).varInt(1).varInt(1<<20).Bytes(), testSecret), nil, nil},
+		{"negative length", sign((&velocityWriter{}).varInt(1).varInt(-1).Bytes(), testSecret), nil, nil},
+		{"overlong VarInt", sign([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, testSecret), nil, nil},
+		{"unsupported version", sign((&velocityWriter{}).header(5, "203.0.113.7").Bytes(), testSecret), nil, nil},
+		{"invalid address", sign((&velocityWriter{}).header(1, "not an address").Bytes(), testSecret), nil, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseVelocity(tt.data, testSecret)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", d)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Fatalf("got error %v, want %v", err, tt.err)
+				}
+				if tt.err == nil && errors.Is(err, ErrBadSignature) {
+					t.Fatalf("got %v for correctly signed data", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(d, tt.want) {
+				t.Errorf("got %+v, want %+v", d, tt.want)
+			}
+		})
+	}
+}
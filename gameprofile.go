@@ -0,0 +1,88 @@
+package mcaccutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A GameProfile is a profile in the JSON format used by AuthLib, the library
+// the Minecraft client and server use to talk to Mojang, and by proxies such
+// as BungeeCord and Velocity. It encodes exactly as AuthLib does, with an
+// undashed id and fields left out when empty, and decodes every form AuthLib
+// accepts, including dashed ids and properties given as an object mapping
+// names to values.
+type GameProfile struct {
+	// UUID is the UUID of the player, without dashes.
+	UUID string
+	// Name is the username of the player.
+	Name string
+	// Properties holds the properties of the profile.
+	Properties []ProfileProperty
+}
+
+// gameProfileJSON is the encoded form of a GameProfile.
+type gameProfileJSON struct {
+	ID         string          `json:"id,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g GameProfile) MarshalJSON() ([]byte, error) {
+	enc := gameProfileJSON{ID: strings.Replace(g.UUID, "-", "", -1), Name: g.Name}
+	if len(g.Properties) > 0 {
+		props, err := json.Marshal(g.Properties)
+		if err != nil {
+			return nil, err
+		}
+		enc.Properties = props
+	}
+	return json.Marshal(enc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *GameProfile) UnmarshalJSON(data []byte) error {
+	var dec gameProfileJSON
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	*g = GameProfile{UUID: strings.ToLower(strings.Replace(dec.ID, "-", "", -1)), Name: dec.Name}
+	props := strings.TrimSpace(string(dec.Properties))
+	switch {
+	case props == "" || props == "null":
+	case strings.HasPrefix(props, "["):
+		return json.Unmarshal(dec.Properties, &g.Properties)
+	case strings.HasPrefix(props, "{"):
+		// The legacy form maps each name to its values, without
+		// signatures.
+		var m map[string][]string
+		if err := json.Unmarshal(dec.Properties, &m); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, v := range m[name] {
+				g.Properties = append(g.Properties, ProfileProperty{Name: name, Value: v})
+			}
+		}
+	default:
+		return fmt.Errorf("mcaccutils: invalid game profile properties %s", props)
+	}
+	return nil
+}
+
+// GameProfile returns p as a GameProfile.
+func (p *Profile) GameProfile() *GameProfile {
+	return &GameProfile{UUID: p.UUID, Name: p.Name, Properties: p.Properties}
+}
+
+// Profile returns g as a Profile.
+func (g *GameProfile) Profile() *Profile {
+	return &Profile{UUID: strings.Replace(g.UUID, "-", "", -1), Name: g.Name, Properties: g.Properties}
+}
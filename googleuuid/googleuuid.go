@@ -0,0 +1,24 @@
+// Package googleuuid converts between the UUID strings used by mcaccutils and
+// the uuid.UUID type of github.com/google/uuid, for programs which already
+// use that package for their database or protocol code.
+package googleuuid
+
+import (
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/google/uuid"
+)
+
+// From returns the uuid.UUID of a UUID as returned by mcaccutils, which may
+// be dashed or undashed.
+func From(s string) (uuid.UUID, error) {
+	b, err := mcaccutils.UUIDBytes(s)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.UUID(b), nil
+}
+
+// String returns u in the undashed form returned by mcaccutils.
+func String(u uuid.UUID) string {
+	return mcaccutils.UUIDFromBytes(u)
+}
@@ -0,0 +1,89 @@
+// Package groupcachestore provides a mcaccutils.Store backed by groupcache, so
+// a fleet of lookup services can share one peer to peer cache. Each missing
+// key is fetched from the Mojang API by exactly one peer, however many
+// services ask for it at once.
+package groupcachestore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/golang/groupcache"
+)
+
+// Store is a mcaccutils.Store which reads through a groupcache group.
+//
+// Values in groupcache can never be changed or removed, so the group key
+// includes the current period of the cache duration, and every entry is
+// fetched again once per period. Set and Delete do nothing.
+type Store struct {
+	group    *groupcache.Group
+	duration time.Duration
+}
+
+// New creates a groupcache group with the given name and cache size in bytes,
+// and returns a Store which reads from it, fetching missing entries with the
+// client c. All peers must use the same group name and cache duration, and the
+// peers themselves are configured through groupcache, for example with
+// groupcache.NewHTTPPool. If the client does not cache, so its CacheDuration
+// is zero, the duration of DefaultConfig is used for the periods.
+//
+// The store is meant to be set as the Store of the Client the program looks
+// players up with. That client is configured before it exists, so c must be
+// a second client, whose rate limit covers the fetches made for the group:
+//
+//	fetcher := mcaccutils.NewClient(mcaccutils.DefaultConfig())
+//	cfg := mcaccutils.DefaultConfig()
+//	cfg.Store = groupcachestore.New(fetcher, "players", 64<<20)
+//	c := mcaccutils.NewClient(cfg)
+func New(c *mcaccutils.Client, name string, cacheBytes int64) *Store {
+	getter := groupcache.GetterFunc(func(ctx context.Context, key string, dest groupcache.Sink) error {
+		// Strip the period from the key. Usernames never contain an @.
+		v, err := c.Fetch(key[:strings.LastIndex(key, "@")])
+		if err != nil {
+			return err
+		}
+		return dest.SetBytes(v)
+	})
+	d := c.Config().CacheDuration
+	if d <= 0 {
+		d = mcaccutils.DefaultConfig().CacheDuration
+	}
+	return &Store{
+		group:    groupcache.NewGroup(name, cacheBytes, getter),
+		duration: d,
+	}
+}
+
+// period returns the suffix added to keys fetched at t.
+func (s *Store) period(t time.Time) string {
+	return "@" + strconv.FormatInt(t.UnixNano()/int64(s.duration), 36)
+}
+
+// Get implements mcaccutils.Store.
+func (s *Store) Get(key string) (value []byte, found bool, err error) {
+	err = s.group.Get(context.Background(), key+s.period(time.Now()), groupcache.AllocatingByteSliceSink(&value))
+	if errors.Is(err, mcaccutils.ErrPlayerNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements mcaccutils.Store. Values are always loaded through the group,
+// so Set does nothing.
+func (s *Store) Set(key string, value []byte, expires time.Time) error {
+	return nil
+}
+
+// Delete implements mcaccutils.Store. Values in a group cannot be removed, so
+// Delete does nothing.
+func (s *Store) Delete(key string) error {
+	return nil
+}
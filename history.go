@@ -0,0 +1,166 @@
+package mcaccutils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A Lookup is the record of a player being resolved through the API.
+type Lookup struct {
+	// Query is the username or UUID which was looked up.
+	Query string
+	// UUID and Name identify the player which was found. They are empty if
+	// NotFound is set.
+	UUID string
+	Name string
+	// NotFound is set if no player was found for Query.
+	NotFound bool
+	// Time is when the lookup was made.
+	Time time.Time
+}
+
+// A History records the lookups made by a Client, building up a local record
+// of which names belonged to which players over time.
+type History interface {
+	// Record saves l.
+	Record(l Lookup) error
+}
+
+// record passes the result of a lookup of query through the API to the
+// history of the client, if it has one. Lookups which failed for a reason
+// other than the player not existing are not recorded.
+func (c *Client) record(query string, p *playerCacheData, err error) {
+	h := c.Config().History
+	if h == nil {
+		return
+	}
+	l := Lookup{Query: query, Time: c.now()}
+	switch {
+	case err == nil:
+		l.UUID, l.Name = p.UUID, p.Username
+	case errors.Is(err, ErrPlayerNotFound):
+		l.NotFound = true
+	default:
+		return
+	}
+	h.Record(l)
+}
+
+// SQLHistory is a History which keeps lookups in the mcaccutils_history table
+// of a SQL database, through database/sql. The caller is responsible for
+// importing a driver; SQLite is a good choice for a single machine.
+type SQLHistory struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLHistory returns a SQLHistory using the database db, which is of the
+// specified dialect. MigrateSQL must have been called on the database before
+// the history is used.
+func NewSQLHistory(db *sql.DB, d Dialect) *SQLHistory {
+	return &SQLHistory{db: db, dialect: d}
+}
+
+// Record implements History.
+func (h *SQLHistory) Record(l Lookup) error {
+	p := h.dialect.Placeholder
+	_, err := h.db.Exec(
+		"INSERT INTO mcaccutils_history (query, uuid, name, name_key, not_found, looked_up) "+
+			"VALUES ("+p(1)+", "+p(2)+", "+p(3)+", "+p(4)+", "+p(5)+", "+p(6)+")",
+		l.Query, l.UUID, l.Name, strings.ToLower(l.Name), l.NotFound, l.Time.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: recording lookup of %q: %w", l.Query, err)
+	}
+	return nil
+}
+
+// Lookups returns every recorded successful lookup which found the player
+// with the given name or UUID, oldest first.
+func (h *SQLHistory) Lookups(query string) ([]Lookup, error) {
+	p := h.dialect.Placeholder
+	rows, err := h.db.Query(
+		"SELECT query, uuid, name, looked_up FROM mcaccutils_history "+
+			"WHERE NOT not_found AND (uuid = "+p(1)+" OR name_key = "+p(2)+") ORDER BY looked_up",
+		strings.Replace(query, "-", "", -1), strings.ToLower(query),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading lookups of %q: %w", query, err)
+	}
+	defer rows.Close()
+	var lookups []Lookup
+	for rows.Next() {
+		var (
+			l  Lookup
+			ts int64
+		)
+		if err := rows.Scan(&l.Query, &l.UUID, &l.Name, &ts); err != nil {
+			return nil, fmt.Errorf("mcaccutils: reading lookups of %q: %w", query, err)
+		}
+		l.Time = time.Unix(ts, 0)
+		lookups = append(lookups, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading lookups of %q: %w", query, err)
+	}
+	return lookups, nil
+}
+
+// NameAt returns the name the player with the given UUID had at time t,
+// according to the most recent lookup recorded at or before t. It returns
+// ErrPlayerNotFound if there is no such lookup.
+func (h *SQLHistory) NameAt(uuid string, t time.Time) (name string, err error) {
+	p := h.dialect.Placeholder
+	err = h.db.QueryRow(
+		"SELECT name FROM mcaccutils_history WHERE NOT not_found AND uuid = "+p(1)+
+			" AND looked_up <= "+p(2)+" ORDER BY looked_up DESC LIMIT 1",
+		strings.Replace(uuid, "-", "", -1), t.Unix(),
+	).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrPlayerNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("mcaccutils: reading name of %s at %v: %w", uuid, t, err)
+	}
+	return name, nil
+}
+
+// Name implements OwnerSource.
+func (h *SQLHistory) Name() string {
+	return "history"
+}
+
+// PreviousOwners implements OwnerSource, returning every player a recorded
+// lookup found with the given name, seen between their first and last such
+// lookups.
+func (h *SQLHistory) PreviousOwners(ctx context.Context, name string) ([]NameOwner, error) {
+	rows, err := h.db.QueryContext(ctx,
+		"SELECT uuid, MIN(looked_up), MAX(looked_up) FROM mcaccutils_history "+
+			"WHERE NOT not_found AND name_key = "+h.dialect.Placeholder(1)+" GROUP BY uuid",
+		strings.ToLower(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading owners of %q: %w", name, err)
+	}
+	defer rows.Close()
+	var owners []NameOwner
+	for rows.Next() {
+		var (
+			o           NameOwner
+			first, last int64
+		)
+		if err := rows.Scan(&o.UUID, &first, &last); err != nil {
+			return nil, fmt.Errorf("mcaccutils: reading owners of %q: %w", name, err)
+		}
+		o.FirstSeen, o.LastSeen = time.Unix(first, 0), time.Unix(last, 0)
+		owners = append(owners, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading owners of %q: %w", name, err)
+	}
+	return owners, nil
+}
@@ -0,0 +1,120 @@
+package mcaccutils
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ErrNotJoined is returned by ValidateLogin when the session server has no
+// record of the player joining the server, because the client did not
+// authenticate, the server ID does not match, or the IP address differs.
+var ErrNotJoined = errors.New("mcaccutils: player has not joined")
+
+// uncachedKey is the context key marking requests whose responses must never
+// be served from or stored in the response cache.
+type uncachedKey struct{}
+
+// uncached reports whether the request with context ctx skips the response
+// cache.
+func uncached(ctx context.Context) bool {
+	skip, _ := ctx.Value(uncachedKey{}).(bool)
+	return skip
+}
+
+// ServerHash returns the server ID a client and server agree on during login,
+// from the server ID string the server sent, which is empty for modern
+// servers, the shared secret and the encoded public key of the server. It is
+// the SHA-1 digest of the three read as a signed number in hexadecimal, as
+// the game computes it.
+func ServerHash(serverID string, sharedSecret, publicKey []byte) string {
+	h := sha1.New()
+	h.Write([]byte(serverID))
+	h.Write(sharedSecret)
+	h.Write(publicKey)
+	sum := h.Sum(nil)
+	n := new(big.Int).SetBytes(sum)
+	if sum[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(sum)*8)))
+	}
+	return n.Text(16)
+}
+
+// ValidateLogin asks the session server whether the player with the
+// specified username has joined the server with the specified server ID, as
+// computed by ServerHash, and returns their profile with its properties
+// signed by the session server, ready to be forwarded to a backend server.
+// It returns ErrNotJoined if the player has not joined.
+//
+// If clientIP is not empty, the session server also checks that the player
+// authenticated from that address, as the prevent-proxy-connections server
+// setting does. It may include a port, which is ignored.
+//
+// The check is made at once and only once: it does not wait for the rate
+// limiter behind queued lookups, as the player is waiting to join, and it is
+// not retried, as the game server gives up on the login long before a retry
+// could succeed. The response is never cached, and the player is remembered
+// by the cache of the client like Remember does, as the session server
+// vouches for them.
+func (c *Client) ValidateLogin(username, serverID, clientIP string) (*Profile, error) {
+	return c.ValidateLoginContext(context.Background(), username, serverID, clientIP)
+}
+
+// ValidateLoginContext is like ValidateLogin, but gives up when ctx is done.
+func (c *Client) ValidateLoginContext(ctx context.Context, username, serverID, clientIP string) (profile *Profile, err error) {
+	defer func() { c.metrics.countError(err) }()
+	q := url.Values{"username": {username}, "serverId": {serverID}}
+	if clientIP != "" {
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+		q.Set("ip", clientIP)
+	}
+	endpoint := c.sessionServerURL() + "/session/minecraft/hasJoined"
+	ctx = context.WithValue(ctx, uncachedKey{}, true)
+	ctx = context.WithValue(ctx, unlimitedKey{}, true)
+	ctx = WithRetryPolicy(ctx, RetryPolicy{MaxAttempts: 1})
+	resp, err := c.get(ctx, endpoint+"?"+q.Encode(), username)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, canceled(ctx)
+		}
+		return nil, lookupError(endpoint, username, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, lookupError(endpoint, username, err)
+	}
+	// Players who have not joined get an empty response.
+	if resp.StatusCode == http.StatusNoContent || (resp.StatusCode == http.StatusOK && len(body) == 0) {
+		return nil, ErrNotJoined
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, username, statusError(resp))
+	}
+	p := &Profile{}
+	if err := c.decodeJSON(endpoint, body, p); err != nil {
+		return nil, err
+	}
+	if p.UUID == "" {
+		return nil, ErrNotJoined
+	}
+	c.Remember(p.UUID, p.Name)
+	return p, nil
+}
+
+// ValidateLogin calls ValidateLogin on the default client.
+func ValidateLogin(username, serverID, clientIP string) (*Profile, error) {
+	return defaultClient.ValidateLogin(username, serverID, clientIP)
+}
+
+// ValidateLoginContext calls ValidateLoginContext on the default client.
+func ValidateLoginContext(ctx context.Context, username, serverID, clientIP string) (*Profile, error) {
+	return defaultClient.ValidateLoginContext(ctx, username, serverID, clientIP)
+}
@@ -0,0 +1,73 @@
+// Package keyring provides a mcaccutils.TokenStore backed by the keyring of
+// the operating system: the Keychain on macOS, the Credential Manager on
+// Windows, and the Secret Service, such as GNOME Keyring or KWallet, on
+// Linux and BSD. Sessions saved there are encrypted at rest, unlike those in
+// a mcaccutils.FileTokenStore.
+package keyring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/zalando/go-keyring"
+)
+
+// DefaultService is the service name sessions are saved under if Store.Service
+// is empty.
+const DefaultService = "mcaccutils"
+
+// Store is a mcaccutils.TokenStore which saves each session as a secret in the
+// keyring, named after its account.
+type Store struct {
+	// Service groups the secrets of a program in the keyring, and is shown
+	// to the user by keyring managers. Programs should set it to their own
+	// name.
+	Service string
+}
+
+// service returns the service name secrets are saved under.
+func (s *Store) service() string {
+	if s.Service == "" {
+		return DefaultService
+	}
+	return s.Service
+}
+
+// Load implements mcaccutils.TokenStore.
+func (s *Store) Load(account string) (*mcaccutils.Session, error) {
+	secret, err := keyring.Get(s.service(), account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, mcaccutils.ErrNoSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keyring: loading session of %s: %w", account, err)
+	}
+	session := &mcaccutils.Session{}
+	if err := json.Unmarshal([]byte(secret), session); err != nil {
+		return nil, fmt.Errorf("keyring: loading session of %s: %w", account, err)
+	}
+	return session, nil
+}
+
+// Save implements mcaccutils.TokenStore.
+func (s *Store) Save(account string, session *mcaccutils.Session) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("keyring: saving session of %s: %w", account, err)
+	}
+	if err := keyring.Set(s.service(), account, string(b)); err != nil {
+		return fmt.Errorf("keyring: saving session of %s: %w", account, err)
+	}
+	return nil
+}
+
+// Delete implements mcaccutils.TokenStore.
+func (s *Store) Delete(account string) error {
+	err := keyring.Delete(s.service(), account)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("keyring: deleting session of %s: %w", account, err)
+	}
+	return nil
+}
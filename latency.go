@@ -0,0 +1,92 @@
+package mcaccutils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindow is the number of recent requests to each endpoint the latency
+// percentiles are computed from.
+const latencyWindow = 1000
+
+// LatencyStats summarises the time recent requests to an endpoint took to
+// respond, not counting time spent waiting for the rate limiter.
+type LatencyStats struct {
+	// Count is the number of requests the percentiles are computed from.
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// latencies keeps the latencies of the most recent requests to each endpoint.
+type latencies struct {
+	mu        sync.Mutex
+	endpoints map[string]*latencyRing
+}
+
+// latencyRing is a fixed size ring buffer of latencies.
+type latencyRing struct {
+	samples []time.Duration
+	next    int
+}
+
+// add records a request to endpoint which took d.
+func (l *latencies) add(endpoint string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.endpoints == nil {
+		l.endpoints = make(map[string]*latencyRing)
+	}
+	r, ok := l.endpoints[endpoint]
+	if !ok {
+		r = &latencyRing{}
+		l.endpoints[endpoint] = r
+	}
+	if len(r.samples) < latencyWindow {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % latencyWindow
+}
+
+// stats returns the statistics of every endpoint.
+func (l *latencies) stats() map[string]LatencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := make(map[string]LatencyStats, len(l.endpoints))
+	for endpoint, r := range l.endpoints {
+		sorted := append([]time.Duration(nil), r.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		at := func(p float64) time.Duration {
+			return sorted[int(p*float64(len(sorted)-1))]
+		}
+		stats[endpoint] = LatencyStats{
+			Count: len(sorted),
+			P50:   at(0.5),
+			P90:   at(0.9),
+			P99:   at(0.99),
+			Max:   sorted[len(sorted)-1],
+		}
+	}
+	return stats
+}
+
+// Latency returns latency statistics for each endpoint the client has sent
+// requests to, keyed by host and path with the query of lookups replaced by
+// {query}. Comparing them with the cache hit rate shows whether slow lookups
+// are caused by the API or by the cache.
+//
+// If the client publishes its counters with expvar, the statistics are also
+// published under latency, in seconds.
+func (c *Client) Latency() map[string]LatencyStats {
+	return c.latency.stats()
+}
+
+// Latency calls Latency on the default client.
+func Latency() map[string]LatencyStats {
+	return defaultClient.Latency()
+}
@@ -0,0 +1,253 @@
+// Package launcher finds the accounts signed in to the official Minecraft
+// launcher on this computer, from the launcher_accounts.json and
+// launcher_profiles.json files in its game directory, so desktop tools can
+// fill in the name and UUID of the player without asking.
+//
+// Only the identity of the accounts is read. The launcher's access tokens
+// are left alone: they belong to the launcher, which refreshes them, and
+// tools wanting a session of their own should sign in with
+// mcaccutils.LoginWithDeviceCode.
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// The files in the game directory accounts are read from. Newer launchers
+// keep accounts in AccountsFile, or in StoreAccountsFile when installed from
+// the Microsoft Store, and older ones in the authentication database of
+// ProfilesFile.
+const (
+	AccountsFile      = "launcher_accounts.json"
+	StoreAccountsFile = "launcher_accounts_microsoft_store.json"
+	ProfilesFile      = "launcher_profiles.json"
+)
+
+// An Account is an account signed in to the launcher.
+type Account struct {
+	// Name and UUID are the username and undashed UUID of the player. They
+	// are empty for accounts which do not own the game.
+	Name string
+	UUID string
+	// Username is the email address or gamertag the account signs in with,
+	// if the launcher recorded it.
+	Username string
+	// Type is the kind of account, such as "Xbox" for Microsoft accounts
+	// or "Mojang" for legacy ones, if the launcher recorded it.
+	Type string
+	// Active reports whether the account is the one the launcher plays as.
+	Active bool
+}
+
+// Dir returns the default game directory of the launcher: %APPDATA%\.minecraft
+// on Windows, ~/Library/Application Support/minecraft on macOS, and
+// ~/.minecraft elsewhere.
+func Dir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, ".minecraft"), nil
+		}
+		return "", errors.New("launcher: %APPDATA% is not set")
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("launcher: %w", err)
+		}
+		return filepath.Join(home, "Library", "Application Support", "minecraft"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("launcher: %w", err)
+	}
+	return filepath.Join(home, ".minecraft"), nil
+}
+
+type launcherAccounts struct {
+	Accounts map[string]struct {
+		Username         string `json:"username"`
+		Type             string `json:"type"`
+		MinecraftProfile *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"minecraftProfile"`
+	} `json:"accounts"`
+	ActiveAccountLocalID string `json:"activeAccountLocalId"`
+}
+
+type launcherProfiles struct {
+	AuthenticationDatabase map[string]struct {
+		Username string `json:"username"`
+		Profiles map[string]struct {
+			DisplayName string `json:"displayName"`
+		} `json:"profiles"`
+	} `json:"authenticationDatabase"`
+	SelectedUser struct {
+		Account string `json:"account"`
+		Profile string `json:"profile"`
+	} `json:"selectedUser"`
+}
+
+// ReadAccounts reads the accounts in the format of launcher_accounts.json,
+// sorted by name.
+func ReadAccounts(r io.Reader) ([]Account, error) {
+	var dec launcherAccounts
+	if err := json.NewDecoder(r).Decode(&dec); err != nil {
+		return nil, fmt.Errorf("launcher: %w", err)
+	}
+	var accounts []Account
+	for id, a := range dec.Accounts {
+		acc := Account{Username: a.Username, Type: a.Type, Active: id == dec.ActiveAccountLocalID}
+		if p := a.MinecraftProfile; p != nil {
+			acc.Name, acc.UUID = p.Name, undash(p.ID)
+		}
+		accounts = append(accounts, acc)
+	}
+	sortAccounts(accounts)
+	return accounts, nil
+}
+
+// ReadProfiles reads the accounts in the authentication database of
+// launcher_profiles.json, as written by older launchers, sorted by name.
+// Accounts with several profiles give one Account for each.
+func ReadProfiles(r io.Reader) ([]Account, error) {
+	var dec launcherProfiles
+	if err := json.NewDecoder(r).Decode(&dec); err != nil {
+		return nil, fmt.Errorf("launcher: %w", err)
+	}
+	var accounts []Account
+	for id, a := range dec.AuthenticationDatabase {
+		if len(a.Profiles) == 0 {
+			accounts = append(accounts, Account{Username: a.Username, Active: id == dec.SelectedUser.Account})
+		}
+		for uuid, p := range a.Profiles {
+			accounts = append(accounts, Account{
+				Name:     p.DisplayName,
+				UUID:     undash(uuid),
+				Username: a.Username,
+				Active:   id == dec.SelectedUser.Account && uuid == dec.SelectedUser.Profile,
+			})
+		}
+	}
+	sortAccounts(accounts)
+	return accounts, nil
+}
+
+// Accounts returns the accounts signed in to the launcher with the game
+// directory dir, or the default one from Dir if dir is empty, sorted by name.
+// It reads every accounts file present, listing an account found in more
+// than one once and trusting the newest file about which is active. It
+// returns an error matching os.ErrNotExist if there are none.
+func Accounts(dir string) ([]Account, error) {
+	if dir == "" {
+		var err error
+		if dir, err = Dir(); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		accounts []Account
+		found    bool
+		// active is set once a file names the active account, as older
+		// files may still name another.
+		active bool
+	)
+	seen := make(map[string]int)
+	for _, f := range []struct {
+		name string
+		read func(io.Reader) ([]Account, error)
+	}{
+		{AccountsFile, ReadAccounts},
+		{StoreAccountsFile, ReadAccounts},
+		{ProfilesFile, ReadProfiles},
+	} {
+		as, err := readFile(filepath.Join(dir, f.name), f.read)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		fileActive := false
+		for _, a := range as {
+			if active {
+				a.Active = false
+			}
+			fileActive = fileActive || a.Active
+			key := a.UUID
+			if key == "" {
+				key = "username:" + a.Username
+			}
+			if i, ok := seen[key]; ok {
+				// Keep the first copy, which comes from the newer file, but
+				// fill in what it lacks.
+				accounts[i].Active = accounts[i].Active || a.Active
+				if accounts[i].Username == "" {
+					accounts[i].Username = a.Username
+				}
+				continue
+			}
+			seen[key] = len(accounts)
+			accounts = append(accounts, a)
+		}
+		active = active || fileActive
+	}
+	if !found {
+		return nil, fmt.Errorf("launcher: no accounts files in %s: %w", dir, os.ErrNotExist)
+	}
+	sortAccounts(accounts)
+	return accounts, nil
+}
+
+// Active returns the account the launcher with the game directory dir, or the
+// default one if dir is empty, plays as, and whether there is one.
+func Active(dir string) (a Account, ok bool, err error) {
+	accounts, err := Accounts(dir)
+	if err != nil {
+		return Account{}, false, err
+	}
+	for _, a := range accounts {
+		if a.Active {
+			return a, true, nil
+		}
+	}
+	return Account{}, false, nil
+}
+
+// readFile reads the accounts in the file at path with read.
+func readFile(path string, read func(io.Reader) ([]Account, error)) ([]Account, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	accounts, err := read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, path)
+	}
+	return accounts, nil
+}
+
+// sortAccounts sorts accounts by name, then by username.
+func sortAccounts(accounts []Account) {
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Name != accounts[j].Name {
+			return accounts[i].Name < accounts[j].Name
+		}
+		return accounts[i].Username < accounts[j].Username
+	})
+}
+
+// undash returns uuid without dashes, in lower case.
+func undash(uuid string) string {
+	return strings.ToLower(strings.Replace(uuid, "-", "", -1))
+}
@@ -0,0 +1,159 @@
+package mcaccutils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket rate limiter. The bucket holds up to n tokens and
+// is refilled at n tokens per period, and every request takes one token.
+//
+// An adaptive limiter changes n as it goes: it is halved whenever the server
+// reports that the limit was exceeded, and grows by one after every n
+// successful requests, up to max.
+type limiter struct {
+	mu       sync.Mutex
+	n        float64
+	max      float64
+	per      time.Duration
+	adaptive bool
+	tokens   float64
+	last     time.Time
+	clock    Clock
+}
+
+// newLimiter returns a limiter allowing n requests per period, as told by
+// clock, or nil, which allows every request, if n is not positive. If
+// adaptive is set, the limit may grow up to max.
+func newLimiter(clock Clock, n int, per time.Duration, adaptive bool, max int) *limiter {
+	if n <= 0 || per <= 0 {
+		return nil
+	}
+	if max < n {
+		max = n
+	}
+	return &limiter{
+		n:        float64(n),
+		max:      float64(max),
+		per:      per,
+		adaptive: adaptive,
+		tokens:   float64(n),
+		last:     clock.Now(),
+		clock:    clock,
+	}
+}
+
+// rate returns the number of tokens added per second. l.mu must be held.
+func (l *limiter) rate() float64 {
+	return l.n / l.per.Seconds()
+}
+
+// refill adds the tokens gained since the last refill. l.mu must be held.
+func (l *limiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate()
+	if l.tokens > l.n {
+		l.tokens = l.n
+	}
+	l.last = now
+}
+
+// Limit returns the current number of requests allowed per period, or zero
+// if there is no limit.
+func (l *limiter) Limit() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.n)
+}
+
+// Success records a request which was not rejected for exceeding the limit.
+func (l *limiter) Success() {
+	if l == nil || !l.adaptive {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.n += 1 / l.n
+	if l.n > l.max {
+		l.n = l.max
+	}
+}
+
+// Exceeded records a request which was rejected for exceeding the limit. The
+// limit is halved and the bucket emptied, pausing requests for a while.
+func (l *limiter) Exceeded() {
+	if l == nil || !l.adaptive {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.clock.Now())
+	l.n /= 2
+	if l.n < 1 {
+		l.n = 1
+	}
+	if l.tokens > 0 {
+		l.tokens = 0
+	}
+}
+
+// Wait blocks until a request is allowed, or until ctx is done.
+func (l *limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	l.refill(l.clock.Now())
+	// Take the token straight away, so waiting requests queue up in order.
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate() * float64(time.Second))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-l.clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		// Give the token back for the next request.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Allow takes a token and reports true if one is available straight away,
+// and reports false without waiting otherwise.
+func (l *limiter) Allow() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.clock.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Estimate returns how long it would take before n more requests are
+// allowed, if no other requests were made.
+func (l *limiter) Estimate(n int) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.clock.Now())
+	wait := (float64(n) - l.tokens) / l.rate()
+	if wait <= 0 {
+		return 0
+	}
+	return time.Duration(wait * float64(time.Second))
+}
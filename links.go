@@ -0,0 +1,163 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GeyserLinkURL is the base URL of the GeyserMC global linking API, used to
+// find linked accounts when Config.LinkProvider and Config.LinkURL are unset.
+const GeyserLinkURL = "https://api.geysermc.org/v2"
+
+// ErrNotLinked is returned when an account is not linked to an account of the
+// other edition.
+var ErrNotLinked = errors.New("mcaccutils: account not linked")
+
+// A LinkedAccount is a Java Edition account and the Bedrock Edition account
+// linked to it, as servers running Geyser let players join with either.
+type LinkedAccount struct {
+	// JavaUUID is the undashed UUID of the Java Edition account.
+	JavaUUID string
+	// JavaName is the name of the Java Edition account, as last seen by the
+	// link provider, so it may be out of date. It is empty if the provider
+	// does not know it.
+	JavaName string
+	// XUID is the Xbox user ID of the Bedrock Edition account, in decimal.
+	XUID string
+	// Gamertag is the Xbox gamertag of the Bedrock Edition account, or empty
+	// if the provider does not know it.
+	Gamertag string
+}
+
+// A LinkProvider is a service recording which Java Edition and Bedrock
+// Edition accounts belong to the same player, such as the GeyserMC global
+// linking API or MCProfile. Both methods return an error matching
+// ErrNotLinked for accounts without a link.
+type LinkProvider interface {
+	// BedrockLink returns the link of the Java Edition account with the
+	// undashed UUID uuid.
+	BedrockLink(ctx context.Context, uuid string) (*LinkedAccount, error)
+	// JavaLink returns the link of the Bedrock Edition account with the Xbox
+	// user ID xuid.
+	JavaLink(ctx context.Context, xuid string) (*LinkedAccount, error)
+}
+
+// linkProvider returns the link provider of the client.
+func (c *Client) linkProvider() LinkProvider {
+	if p := c.Config().LinkProvider; p != nil {
+		return p
+	}
+	return geyserLink{c}
+}
+
+// LinkedBedrockAccount returns the Bedrock Edition account linked to the Java
+// Edition account with the specified UUID, which may be dashed or undashed,
+// as recorded by Config.LinkProvider. It returns an error matching
+// ErrNotLinked if the account has no link.
+func (c *Client) LinkedBedrockAccount(ctx context.Context, uuid string) (*LinkedAccount, error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return c.linkProvider().BedrockLink(ctx, formatUUID(b))
+}
+
+// LinkedBedrockAccount calls LinkedBedrockAccount on the default client.
+func LinkedBedrockAccount(ctx context.Context, uuid string) (*LinkedAccount, error) {
+	return defaultClient.LinkedBedrockAccount(ctx, uuid)
+}
+
+// LinkedJavaAccount returns the Java Edition account linked to the Bedrock
+// Edition account with the specified Xbox user ID, as recorded by
+// Config.LinkProvider. It returns an error matching ErrNotLinked if the
+// account has no link.
+func (c *Client) LinkedJavaAccount(ctx context.Context, xuid string) (*LinkedAccount, error) {
+	if _, err := strconv.ParseUint(xuid, 10, 64); err != nil {
+		return nil, fmt.Errorf("mcaccutils: invalid XUID %q", xuid)
+	}
+	return c.linkProvider().JavaLink(ctx, xuid)
+}
+
+// LinkedJavaAccount calls LinkedJavaAccount on the default client.
+func LinkedJavaAccount(ctx context.Context, xuid string) (*LinkedAccount, error) {
+	return defaultClient.LinkedJavaAccount(ctx, xuid)
+}
+
+// geyserLink is the LinkProvider for the GeyserMC global linking API, which
+// sends its requests through a client.
+type geyserLink struct {
+	c *Client
+}
+
+// geyserLinkResponse is a link as returned by the GeyserMC API.
+type geyserLinkResponse struct {
+	BedrockID      json.Number `json:"bedrock_id"`
+	JavaID         string      `json:"java_id"`
+	JavaName       string      `json:"java_name"`
+	LastNameUpdate int64       `json:"last_name_update"`
+}
+
+// BedrockLink implements LinkProvider.
+func (g geyserLink) BedrockLink(ctx context.Context, uuid string) (*LinkedAccount, error) {
+	dashed, err := DashUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return g.link(ctx, "/link/java/"+url.PathEscape(dashed), uuid)
+}
+
+// JavaLink implements LinkProvider.
+func (g geyserLink) JavaLink(ctx context.Context, xuid string) (*LinkedAccount, error) {
+	return g.link(ctx, "/link/bedrock/"+url.PathEscape(xuid), xuid)
+}
+
+// link fetches the link at path, made to look up query. The API answers with
+// an empty object, or an empty list for Java Edition accounts, if there is
+// no link. GeyserMC is not Mojang, so the request does not wait for or count
+// towards the rate limit of the client, and its statuses do not adjust it.
+func (g geyserLink) link(ctx context.Context, path, query string) (*LinkedAccount, error) {
+	endpoint := GeyserLinkURL
+	if u := g.c.Config().LinkURL; u != "" {
+		endpoint = strings.TrimSuffix(u, "/")
+	}
+	endpoint += path
+	resp, err := g.c.get(context.WithValue(ctx, unlimitedKey{}, true), endpoint, query)
+	if err != nil {
+		return nil, lookupError(endpoint, query, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, query, statusError(resp))
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, lookupError(endpoint, query, err)
+	}
+	var links []geyserLinkResponse
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		err = g.c.decodeJSON(endpoint, body, &links)
+	} else {
+		links = make([]geyserLinkResponse, 1)
+		err = g.c.decodeJSON(endpoint, body, &links[0])
+	}
+	if err != nil {
+		return nil, lookupError(endpoint, query, err)
+	}
+	if len(links) == 0 || links[0].JavaID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotLinked, query)
+	}
+	l := links[0]
+	return &LinkedAccount{
+		JavaUUID: strings.ToLower(strings.Replace(l.JavaID, "-", "", -1)),
+		JavaName: l.JavaName,
+		XUID:     l.BedrockID.String(),
+	}, nil
+}
@@ -1,12 +1,14 @@
 package mcaccutils
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/pmylund/go-cache"
 	"io/ioutil"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 )
@@ -15,117 +17,373 @@ var (
 	// ErrPlayerNotFound is an error returned when no player is found for the
 	// specified query.
 	ErrPlayerNotFound = errors.New("mcaccutils: player not found")
+)
+
+// lookupError adds the endpoint and query of a failed lookup to err, which
+// remains available through errors.Is and errors.As.
+func lookupError(endpoint, query string, err error) error {
+	return fmt.Errorf("mcaccutils: looking up %q at %s: %w", query, endpoint, err)
+}
 
+// The variables below configure the client used by the package level
+// functions. They are read every time that client is used, so changing them
+// while lookups are running is racy.
+var (
 	// CacheDuration can be used to modify the duration fetched names and UUIDs
-	// are cached for. Making this duration very short can make it much easier
-	// to go over the Mojang rate limits, so it is not recommended.
+	// are cached for.
+	//
+	// Deprecated: Use Config.CacheDuration with NewClient instead.
 	CacheDuration = 12 * time.Hour
 
-	// dataCache is the memory cache for all names. The default expiration time
-	// means nothing, because CacheDuration is used in all cases when values are
-	// added to the cache.
-	dataCache = cache.New(1*time.Hour, 1*time.Minute)
+	// CacheJitter is the upper bound of a random duration added to
+	// CacheDuration whenever an entry is cached.
+	//
+	// Deprecated: Use Config.CacheJitter with NewClient instead.
+	CacheJitter = 30 * time.Minute
+
+	// NotFoundCacheDuration is the duration a failed lookup is cached for.
+	//
+	// Deprecated: Use Config.NotFoundCacheDuration with NewClient instead.
+	NotFoundCacheDuration = 10 * time.Minute
+
+	// ExternalStore, if set, is used as a second level cache behind the
+	// memory cache.
+	//
+	// Deprecated: Use Config.Store with NewClient instead.
+	ExternalStore Store
+
+	// StoreCodec is the Codec used to encode the values saved to
+	// ExternalStore.
+	//
+	// Deprecated: Use Config.StoreCodec with NewClient instead.
+	StoreCodec = JSONCodec
 )
 
-type playerCacheData struct {
-	UUID     string
-	Username string
+// A NameChange is an entry in the name history of a player.
+type NameChange struct {
+	// Name is the username the player changed to.
+	Name string
+	// ChangedAt is when the player changed to Name. It is the zero time for
+	// the name the account was created with.
+	ChangedAt time.Time
 }
 
-// GetNames produces a list of all usernames ever owned by the specified UUID, in
-// unspecified order.
+type mojangNameHistoryEntry struct {
+	Name        string `json:"name"`
+	ChangedToAt int64  `json:"changedToAt"`
+}
+
+// GetNameHistory returns every username ever owned by the specified UUID,
+// oldest first, so the last entry holds the current name.
 //
-// The result of this function is not cached, so it should be used with caution
-// so as to avoid running into the Mojang rate limit.
-func GetNames(uuid string) (names []string, err error) {
+// Mojang retired the name history endpoint in September 2022, so with the
+// Mojang API GetNameHistory returns a *RetiredError without sending a
+// request. Some third party authentication servers still serve it.
+//
+// The result of this function is only kept in the short lived response cache,
+// so it should be used with caution so as to avoid running into the Mojang
+// rate limit.
+func (c *Client) GetNameHistory(uuid string) (history []NameChange, err error) {
+	return c.getNameHistory(context.Background(), uuid)
+}
+
+// getNameHistory implements GetNameHistory, giving up when ctx is done.
+func (c *Client) getNameHistory(ctx context.Context, uuid string) (history []NameChange, err error) {
+	defer func() { c.metrics.countError(err) }()
+	if err := c.checkEndpoint(FeatureNameHistory, c.apiURL()); err != nil {
+		return nil, err
+	}
 	uuid = strings.Replace(uuid, "-", "", -1)
 	// Fetch the account info API for this player UUID.
-	resp, err := http.Get(fmt.Sprintf("https://api.mojang.com/user/profiles/%s/names", uuid))
+	endpoint := fmt.Sprintf("%s/user/profiles/%s/names", c.apiURL(), uuid)
+	resp, err := c.get(ctx, endpoint, uuid)
 	if err != nil {
-		return nil, err
+		return nil, lookupError(endpoint, uuid, err)
 	}
 	defer resp.Body.Close()
 	// Read out the body.
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
+		return nil, lookupError(endpoint, uuid, err)
+	}
+	if err := c.checkRetired(FeatureNameHistory, c.apiURL(), resp); err != nil {
 		return nil, err
 	}
+	// Unknown UUIDs get an empty response.
+	if resp.StatusCode == http.StatusNoContent || len(body) == 0 {
+		return nil, ErrPlayerNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, uuid, statusError(resp))
+	}
 	// Decode the JSON
-	var decResp []string
-	err = json.Unmarshal(body, &decResp)
-	if err != nil {
+	var decResp []mojangNameHistoryEntry
+	if err := c.decodeJSON(endpoint, body, &decResp); err != nil {
 		return nil, err
 	}
-	if len(names) == 0 {
+	if len(decResp) == 0 {
 		return nil, ErrPlayerNotFound
 	}
-	// Return the decoded names.
-	return decResp, nil
+	history = make([]NameChange, len(decResp))
+	for i, e := range decResp {
+		history[i].Name = e.Name
+		if e.ChangedToAt != 0 {
+			history[i].ChangedAt = time.Unix(0, e.ChangedToAt*int64(time.Millisecond))
+		}
+	}
+	// The API returns the history in order, but make sure of it, as the
+	// current name is taken from the end.
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].ChangedAt.Before(history[j].ChangedAt)
+	})
+	return history, nil
 }
 
-// GetName returns the first name found by the Mojang API for the specified
-// UUID, or an error if the name cannot be found.
-func GetName(uuid string) (name string, err error) {
+// GetNameHistory calls GetNameHistory on the default client.
+func GetNameHistory(uuid string) (history []NameChange, err error) {
+	return defaultClient.GetNameHistory(uuid)
+}
+
+// GetNames produces a list of all usernames ever owned by the specified UUID,
+// oldest first.
+//
+// The result of this function is only kept in the short lived response cache,
+// so it should be used with caution so as to avoid running into the Mojang
+// rate limit.
+func (c *Client) GetNames(uuid string) (names []string, err error) {
+	history, err := c.GetNameHistory(uuid)
+	if err != nil {
+		return nil, err
+	}
+	names = make([]string, len(history))
+	for i, h := range history {
+		names[i] = h.Name
+	}
+	return names, nil
+}
+
+// GetNames calls GetNames on the default client.
+func GetNames(uuid string) (names []string, err error) {
+	return defaultClient.GetNames(uuid)
+}
+
+// GetName returns the current name of the player with the specified UUID, or
+// an error if the name cannot be found.
+func (c *Client) GetName(uuid string) (name string, err error) {
+	return c.GetNameContext(context.Background(), uuid)
+}
+
+// GetNameContext is like GetName, but gives up when ctx is done, with an
+// error matching ErrCanceled. Requests made for it carry the tag of ctx, if
+// any.
+func (c *Client) GetNameContext(ctx context.Context, uuid string) (name string, err error) {
 	uuid = strings.Replace(uuid, "-", "", -1)
-	if p, found := dataCache.Get(uuid); found {
-		return p.(*playerCacheData).Username, nil
+	if p, found := c.cachedPlayer(uuid); found {
+		if p.notFound {
+			return "", ErrPlayerNotFound
+		}
+		return p.Username, nil
+	}
+	p, err := c.fetchName(ctx, uuid)
+	if cerr := canceled(ctx); err != nil && cerr != nil {
+		return "", cerr
+	}
+	c.shadowName(uuid, p, err)
+	c.record(uuid, p, err)
+	c.publishLookup(uuid, p, err)
+	if errors.Is(err, ErrPlayerNotFound) {
+		c.cacheNotFound(uuid)
 	}
-	names, err := GetNames(uuid)
 	if err != nil {
 		return "", err
 	}
-	p := &playerCacheData{UUID: uuid, Username: names[0]}
-	dataCache.Add(strings.ToLower(names[0]), p, CacheDuration)
-	dataCache.Add(uuid, p, CacheDuration)
-	return names[0], nil
+	c.cachePlayer(p)
+	return p.Username, nil
+}
+
+// GetName calls GetName on the default client.
+func GetName(uuid string) (name string, err error) {
+	return defaultClient.GetName(uuid)
+}
+
+// GetNameContext calls GetNameContext on the default client.
+func GetNameContext(ctx context.Context, uuid string) (name string, err error) {
+	return defaultClient.GetNameContext(ctx, uuid)
 }
 
-type mojangNameResponse struct {
-	Profiles []mojangNameResponseProfile `json:"profiles"`
-	Count    int                         `json:"size"`
+// fetchName looks up the player with the given UUID using the Mojang API,
+// bypassing the cache.
+func (c *Client) fetchName(ctx context.Context, uuid string) (*playerCacheData, error) {
+	p, err := c.GetProfileContext(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return &playerCacheData{UUID: p.UUID, Username: p.Name}, nil
 }
 
 type mojangNameResponseProfile struct {
 	Name string `json:"name"`
 	UUID string `json:"id"`
+	// Legacy and Demo are only sent for old and unpaid accounts. They are
+	// listed so strict decoding accepts them.
+	Legacy bool `json:"legacy"`
+	Demo   bool `json:"demo"`
 }
 
 // GetUUID takes the player name and returns the UUID of that player, and the
 // case corrected username. It returns a UUID which does not contain dashes (-).
-func GetUUID(n string) (uuid string, name string, err error) {
+// Names no player can have get an *InvalidNameError without contacting the
+// API.
+func (c *Client) GetUUID(n string) (uuid string, name string, err error) {
+	return c.GetUUIDContext(context.Background(), n)
+}
+
+// GetUUIDContext is like GetUUID, but gives up when ctx is done, with an
+// error matching ErrCanceled. Requests made for it carry the tag of ctx, if
+// any.
+func (c *Client) GetUUIDContext(ctx context.Context, n string) (uuid string, name string, err error) {
+	if err := checkName(n); err != nil {
+		return "", "", err
+	}
 	n = strings.ToLower(n)
 	// Try the cache.
-	p, found := dataCache.Get(n)
-	if found {
-		return p.(*playerCacheData).UUID, p.(*playerCacheData).Username, nil
+	if p, found := c.cachedPlayer(n); found {
+		if p.notFound {
+			return "", "", ErrPlayerNotFound
+		}
+		return p.UUID, p.Username, nil
+	}
+	p, err := c.fetchUUID(ctx, n)
+	if cerr := canceled(ctx); err != nil && cerr != nil {
+		return "", "", cerr
+	}
+	c.shadowUUID(n, p, err)
+	c.record(n, p, err)
+	c.publishLookup(n, p, err)
+	if errors.Is(err, ErrPlayerNotFound) {
+		c.cacheNotFound(n)
 	}
-	// Hit the API and wait for a response.
-	reqBody := strings.NewReader(
-		fmt.Sprintf("{\"name\":\"%s\", \"agent\": \"minecraft\"}", n),
-	)
-	resp, err := http.Post("https://api.mojang.com/profiles/page/1", "application/json", reqBody)
 	if err != nil {
 		return "", "", err
 	}
+	c.cachePlayer(p)
+	return p.UUID, p.Username, nil
+}
+
+// GetUUID calls GetUUID on the default client.
+func GetUUID(n string) (uuid string, name string, err error) {
+	return defaultClient.GetUUID(n)
+}
+
+// GetUUIDContext calls GetUUIDContext on the default client.
+func GetUUIDContext(ctx context.Context, n string) (uuid string, name string, err error) {
+	return defaultClient.GetUUIDContext(ctx, n)
+}
+
+// fetchUUID looks up the player with the given name using the API selected
+// by the lookup service, bypassing the cache.
+func (c *Client) fetchUUID(ctx context.Context, n string) (p *playerCacheData, err error) {
+	service := c.Config().LookupService
+	if service == LookupMinecraftServices {
+		return c.fetchUUIDFromServices(ctx, n)
+	}
+	players, err := c.fetchUUIDs(ctx, []string{n})
+	if err != nil {
+		return nil, err
+	}
+	p, ok := players[n]
+	if !ok {
+		return nil, ErrPlayerNotFound
+	}
+	return p, nil
+}
+
+// fetchUUIDs looks up the players with the given lowercased names in a single
+// request to the API selected by the lookup service, bypassing the cache. The
+// players found are keyed by lowercased name; names with no player are left
+// out.
+func (c *Client) fetchUUIDs(ctx context.Context, names []string) (players map[string]*playerCacheData, err error) {
+	defer func() { c.metrics.countError(err) }()
+	service := c.Config().LookupService
+	switch service {
+	case LookupMinecraftServices:
+		return c.postUUIDs(ctx, c.servicesURL(), servicesBulkPath, names)
+	case LookupFailover:
+		return c.failoverUUIDs(ctx, names)
+	}
+	players, err = c.postUUIDs(ctx, c.apiURL(), "/profiles/minecraft", names)
+	if err != nil && service == LookupFallback && canceled(ctx) == nil {
+		players, err = c.postUUIDs(ctx, c.servicesURL(), servicesBulkPath, names)
+	}
+	return players, err
+}
+
+// postUUIDs looks up the players with the given lowercased names by posting
+// them to the bulk lookup endpoint at path of the API at base.
+func (c *Client) postUUIDs(ctx context.Context, base, path string, names []string) (players map[string]*playerCacheData, err error) {
+	if err := c.checkEndpoint(FeatureUUIDLookup, base); err != nil {
+		return nil, err
+	}
+	query := strings.Join(names, ",")
+	// Hit the API and wait for a response.
+	reqBody, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := base + path
+	resp, err := c.post(ctx, endpoint, query, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, lookupError(endpoint, query, err)
+	}
 	defer resp.Body.Close()
 	// Read out the body.
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", "", err
+		return nil, lookupError(endpoint, query, err)
+	}
+	if err := c.checkRetired(FeatureUUIDLookup, base, resp); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, query, statusError(resp))
 	}
 	// Decode the JSON
-	decResp := mojangNameResponse{}
-	err = json.Unmarshal(body, &decResp)
-	if err != nil {
-		return "", "", err
+	var decResp []mojangNameResponseProfile
+	if err := c.decodeJSON(endpoint, body, &decResp); err != nil {
+		return nil, err
+	}
+	players = make(map[string]*playerCacheData, len(decResp))
+	for _, p := range decResp {
+		players[strings.ToLower(p.Name)] = &playerCacheData{
+			UUID:     strings.Replace(p.UUID, "-", "", -1),
+			Username: p.Name,
+		}
+	}
+	return players, nil
+}
+
+// Fetch looks up the player cached under the key k, which is either a
+// lowercased username or an undashed UUID, directly from the Mojang API. It
+// bypasses every cache and returns the player encoded as a Store value, due to
+// expire one cache duration from now.
+//
+// Fetch is intended for Store implementations which load missing values
+// themselves, such as the groupcache store.
+func (c *Client) Fetch(k string) (value []byte, err error) {
+	var p *playerCacheData
+	if len(k) == 32 {
+		p, err = c.fetchName(context.Background(), k)
+	} else {
+		p, err = c.fetchUUID(context.Background(), k)
 	}
-	// Make sure the lookup was a success.
-	if decResp.Count < 1 {
-		return "", "", ErrPlayerNotFound
+	if err != nil {
+		return nil, err
 	}
-	u := strings.Replace(decResp.Profiles[0].UUID, "-", "", -1)
-	p = &playerCacheData{UUID: u, Username: n}
-	dataCache.Add(n, p, CacheDuration)
-	dataCache.Add(u, p, CacheDuration)
-	return u, decResp.Profiles[0].Name, nil
+	p.Expires = c.now().Add(c.cacheTTL())
+	return c.codec().Marshal(p)
+}
+
+// Fetch calls Fetch on the default client.
+func Fetch(k string) (value []byte, err error) {
+	return defaultClient.Fetch(k)
 }
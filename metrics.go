@@ -0,0 +1,97 @@
+package mcaccutils
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+)
+
+// metrics holds the expvar counters of a Client. A nil *metrics counts
+// nothing.
+type metrics struct {
+	vars   *expvar.Map
+	errors *expvar.Map
+	// tags counts the API calls made for each tag set with WithTag.
+	tags *expvar.Map
+}
+
+// newMetrics publishes a map of counters under name with expvar, or returns
+// nil if name is empty.
+func newMetrics(name string) *metrics {
+	if name == "" {
+		return nil
+	}
+	m := &metrics{vars: expvar.NewMap(name), errors: new(expvar.Map), tags: new(expvar.Map)}
+	m.vars.Set("errors", m.errors)
+	m.vars.Set("api_calls_by_tag", m.tags)
+	return m
+}
+
+// add adds delta to the counter key.
+func (m *metrics) add(key string, delta int64) {
+	if m == nil {
+		return
+	}
+	m.vars.Add(key, delta)
+}
+
+// addTagged adds one to the count of API calls made for tag. Untagged calls
+// are not counted, as api_calls already includes them.
+func (m *metrics) addTagged(tag string) {
+	if m == nil || tag == "" {
+		return
+	}
+	m.tags.Add(tag, 1)
+}
+
+// addError adds one to the count of errors of the given kind.
+func (m *metrics) addError(kind string) {
+	if m == nil {
+		return
+	}
+	m.errors.Add(kind, 1)
+}
+
+// publishLatency publishes the latency statistics returned by stats, in
+// seconds.
+func (m *metrics) publishLatency(stats func() map[string]LatencyStats) {
+	if m == nil {
+		return
+	}
+	m.vars.Set("latency", expvar.Func(func() interface{} {
+		out := make(map[string]map[string]float64)
+		for endpoint, s := range stats() {
+			out[endpoint] = map[string]float64{
+				"count": float64(s.Count),
+				"p50":   s.P50.Seconds(),
+				"p90":   s.P90.Seconds(),
+				"p99":   s.P99.Seconds(),
+				"max":   s.Max.Seconds(),
+			}
+		}
+		return out
+	}))
+}
+
+// countError counts err, if it is not nil, under its type.
+func (m *metrics) countError(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		netErr    net.Error
+	)
+	switch {
+	case errors.Is(err, ErrPlayerNotFound):
+		m.addError("not_found")
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		m.addError("decode")
+	case errors.As(err, &netErr):
+		m.addError("network")
+	default:
+		m.addError("other")
+	}
+}
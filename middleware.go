@@ -0,0 +1,56 @@
+package mcaccutils
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// platformMiddleware is added below the configured middleware of every
+// Client, for requests which need adjusting on some platforms.
+var platformMiddleware []Middleware
+
+// A Doer sends an HTTP request and returns its response. *http.Client is a
+// Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts an ordinary function to a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// A Middleware wraps the Doer every API request of a Client is sent through,
+// so it can change requests, inspect responses or refuse to make a request at
+// all. It returns a Doer which usually calls next.
+type Middleware func(next Doer) Doer
+
+// chain wraps d in the middleware, with the first middleware outermost.
+func chain(d Doer, middleware []Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		d = middleware[i](d)
+	}
+	return d
+}
+
+// URLPrefix returns a Middleware which sends every request to prefix followed
+// by the original URL, which is the form expected by most CORS proxies. It
+// lets code running in a browser reach the Mojang API, which does not allow
+// cross origin requests itself.
+func URLPrefix(prefix string) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			u, err := url.Parse(prefix + req.URL.String())
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.URL = u
+			req.Host = u.Host
+			return next.Do(req)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package mobile is a thin binding layer over mcaccutils for use with
+// gomobile bind. Every function takes and returns only strings and errors, so
+// the generated Java and Objective-C APIs stay simple. Structured results are
+// returned as JSON.
+package mobile
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// Resolver looks up players, caching the results in memory for as long as the
+// Resolver is kept around.
+type Resolver struct {
+	c *mcaccutils.Client
+}
+
+// NewResolver returns a Resolver using the default configuration.
+func NewResolver() *Resolver {
+	return &Resolver{c: mcaccutils.NewClient(mcaccutils.DefaultConfig())}
+}
+
+// NewProxiedResolver returns a Resolver which sends every request to prefix
+// followed by the original URL, for apps which reach the Mojang API through a
+// proxy.
+func NewProxiedResolver(prefix string) *Resolver {
+	cfg := mcaccutils.DefaultConfig()
+	cfg.Middleware = []mcaccutils.Middleware{mcaccutils.URLPrefix(prefix)}
+	return &Resolver{c: mcaccutils.NewClient(cfg)}
+}
+
+// UUID returns the undashed UUID of the player with the given name.
+func (r *Resolver) UUID(name string) (string, error) {
+	uuid, _, err := r.c.GetUUID(name)
+	return uuid, err
+}
+
+// CorrectName returns the name of the player with the given name, with the
+// capitalisation corrected.
+func (r *Resolver) CorrectName(name string) (string, error) {
+	_, name, err := r.c.GetUUID(name)
+	return name, err
+}
+
+// Name returns the current name of the player with the given UUID.
+func (r *Resolver) Name(uuid string) (string, error) {
+	return r.c.GetName(uuid)
+}
+
+// ProfileJSON returns the profile of the player with the given UUID, encoded
+// as JSON in the format served by the session server.
+func (r *Resolver) ProfileJSON(uuid string) (string, error) {
+	p, err := r.c.GetProfile(uuid)
+	if err != nil {
+		return "", err
+	}
+	return marshal(p)
+}
+
+// NameHistoryJSON returns the name history of the player with the given UUID,
+// encoded as a JSON array of objects with the fields Name and ChangedAt,
+// oldest first.
+func (r *Resolver) NameHistoryJSON(uuid string) (string, error) {
+	history, err := r.c.GetNameHistory(uuid)
+	if err != nil {
+		return "", err
+	}
+	return marshal(history)
+}
+
+// Invalidate removes the player with the given name or UUID from the cache.
+func (r *Resolver) Invalidate(query string) {
+	r.c.Invalidate(query)
+}
+
+// IsNotFound reports whether err means that the player does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, mcaccutils.ErrPlayerNotFound)
+}
+
+func marshal(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,89 @@
+package mcaccutils_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/bearbin/go-mcaccutils/providers"
+	"github.com/bearbin/go-mcaccutils/replay"
+)
+
+// replayClient returns a client for the API with the authlib-injector root
+// root, answering requests from the fixtures in dir. Mojang no longer serves
+// name histories, so the fixtures come from a third party API which does.
+func replayClient(root, dir string) *mcaccutils.Client {
+	cfg := mcaccutils.AuthlibInjectorConfig(root)
+	cfg.HTTPClient = &http.Client{Transport: &replay.Transport{Dir: dir}}
+	return mcaccutils.NewClient(cfg)
+}
+
+func TestGetNameHistory(t *testing.T) {
+	c := replayClient(providers.ElyByRoot, "testdata/namehistory")
+	history, err := c.GetNameHistory("7125ba8b-1c86-4508-b92b-b5c042ccfe2b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []mcaccutils.NameChange{
+		{Name: "KrisJelbring"},
+		{Name: "Kris", ChangedAt: time.Unix(1423059020, 0)},
+		{Name: "KrisJ", ChangedAt: time.Unix(1501245830, 0)},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(history), len(want))
+	}
+	for i := range want {
+		if history[i].Name != want[i].Name || !history[i].ChangedAt.Equal(want[i].ChangedAt) {
+			t.Errorf("entry %d is %+v, want %+v", i, history[i], want[i])
+		}
+	}
+}
+
+func TestGetNames(t *testing.T) {
+	c := replayClient(providers.ElyByRoot, "testdata/namehistory")
+	for _, tt := range []struct {
+		uuid  string
+		names []string
+		err   error
+	}{
+		{"853c80ef3c3749fdaa49938b674adae6", []string{"jeb_"}, nil},
+		{"7125ba8b1c864508b92bb5c042ccfe2b", []string{"KrisJelbring", "Kris", "KrisJ"}, nil},
+		{"ffffffffffffffffffffffffffffffff", nil, mcaccutils.ErrPlayerNotFound},
+	} {
+		names, err := c.GetNames(tt.uuid)
+		if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+			t.Errorf("GetNames(%s) returned error %v, want %v", tt.uuid, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("GetNames(%s) = %q, want %q", tt.uuid, names, tt.names)
+		}
+	}
+}
+
+func TestGetNamesRetired(t *testing.T) {
+	c := replayClient(providers.ElyByRoot, "testdata/namehistory")
+	_, err := c.GetNames("069a79f444e94726a5befca90e38aaf5")
+	if !errors.Is(err, mcaccutils.ErrEndpointRetired) {
+		t.Fatalf("got error %v, want one matching ErrEndpointRetired", err)
+	}
+	// The client remembers the endpoint is gone, so no fixture is needed for
+	// the next player.
+	if _, err := c.GetNames("853c80ef3c3749fdaa49938b674adae6"); !errors.Is(err, mcaccutils.ErrEndpointRetired) {
+		t.Errorf("got error %v after retirement, want one matching ErrEndpointRetired", err)
+	}
+}
+
+func TestGetNamesMojang(t *testing.T) {
+	// Mojang is known to have retired the endpoint, so there is no fixture:
+	// a request would fail with a missing fixture error instead.
+	cfg := mcaccutils.DefaultConfig()
+	cfg.HTTPClient = &http.Client{Transport: &replay.Transport{Dir: "testdata/namehistory"}}
+	c := mcaccutils.NewClient(cfg)
+	if _, err := c.GetNames("853c80ef3c3749fdaa49938b674adae6"); !errors.Is(err, mcaccutils.ErrEndpointRetired) {
+		t.Fatalf("got error %v, want one matching ErrEndpointRetired", err)
+	}
+}
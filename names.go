@@ -0,0 +1,159 @@
+package mcaccutils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidName is matched by the errors returned for names which no
+	// player can have. Such names are rejected without contacting the API.
+	ErrInvalidName = errors.New("mcaccutils: invalid name")
+
+	// ErrRateLimited is matched by the errors returned when the API rejects a
+	// request for exceeding its rate limit.
+	ErrRateLimited = errors.New("mcaccutils: rate limited")
+
+	// ErrNameNotAllowed is matched by the errors returned for names the
+	// Mojang filter rejects, for example because they are offensive.
+	ErrNameNotAllowed = errors.New("mcaccutils: name not allowed")
+)
+
+// maxNameLength is the length of the longest name the API accepts in a
+// lookup. Names are limited to 16 characters today, but some older accounts
+// have longer ones.
+const maxNameLength = 25
+
+// The lengths of the names which can be claimed today.
+const (
+	minNewNameLength = 3
+	maxNewNameLength = 16
+)
+
+// An InvalidNameError is returned for a name which no player can have. It
+// matches both ErrInvalidName and ErrPlayerNotFound.
+type InvalidNameError struct {
+	// Name is the rejected name.
+	Name string
+	// Reason describes what is wrong with the name.
+	Reason string
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("mcaccutils: invalid name %q: %s", e.Name, e.Reason)
+}
+
+func (e *InvalidNameError) Is(target error) bool {
+	return target == ErrInvalidName || target == ErrPlayerNotFound
+}
+
+// checkName returns an *InvalidNameError if no player can have the name n.
+func checkName(n string) error {
+	if n == "" {
+		return &InvalidNameError{Name: n, Reason: "empty"}
+	}
+	if len(n) > maxNameLength {
+		return &InvalidNameError{Name: n, Reason: fmt.Sprintf("longer than %d characters", maxNameLength)}
+	}
+	for _, r := range n {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return &InvalidNameError{Name: n, Reason: fmt.Sprintf("contains %q", r)}
+		}
+	}
+	return nil
+}
+
+// CheckNewName returns an *InvalidNameError if the name n cannot be claimed
+// because of its length or characters: names must be 3 to 16 letters,
+// digits and underscores. It does not contact the API, so it cannot tell
+// whether the name is taken or rejected by the Mojang filter; ValidateName
+// checks that too.
+func CheckNewName(n string) error {
+	if err := checkName(n); err != nil {
+		return err
+	}
+	if len(n) < minNewNameLength || len(n) > maxNewNameLength {
+		return &InvalidNameError{Name: n, Reason: fmt.Sprintf("not %d to %d characters long", minNewNameLength, maxNewNameLength)}
+	}
+	return nil
+}
+
+// requestIDHeaders are the response headers which may identify a request to
+// the operators of the API and the networks in front of it, in order of
+// preference.
+var requestIDHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Amz-Cf-Id", "Cf-Ray"}
+
+// A StatusError is returned when the API answers with an unexpected status.
+// It carries the headers operators need to chase the failure up with Mojang
+// and to decide when to try again. It matches ErrRateLimited if the request
+// was rejected for exceeding the rate limit.
+type StatusError struct {
+	// StatusCode and Status are those of the response, such as 503 and
+	// "503 Service Unavailable".
+	StatusCode int
+	Status     string
+	// RetryAfter is the wait asked for by the Retry-After header, or zero if
+	// there was none.
+	RetryAfter time.Duration
+	// RequestID identifies the request to the API, or to the network in
+	// front of it, if the response said so.
+	RequestID string
+	// Header holds all the headers of the response.
+	Header http.Header
+}
+
+func (e *StatusError) Error() string {
+	msg := "unexpected status " + e.Status
+	if e.StatusCode == http.StatusTooManyRequests {
+		msg = ErrRateLimited.Error() + ": " + msg
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
+
+// statusError returns a *StatusError for the unexpected response resp.
+func statusError(resp *http.Response) *StatusError {
+	e := &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: retryAfter(resp.Header),
+		Header:     resp.Header,
+	}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	return e
+}
+
+// retryAfter returns the wait asked for by the Retry-After header in h, which
+// holds either a number of seconds or a date, or zero if there is none.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package mcaccutils
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// OptiFineCapeURL returns the address of the OptiFine cape image of the
+// player with the specified username.
+func OptiFineCapeURL(name string) string {
+	return "http://s.optifine.net/capes/" + name + ".png"
+}
+
+// HasOptiFineCape reports whether the player with the specified username has
+// an OptiFine cape, which is served from OptiFineCapeURL. Results are cached
+// for the OptiFineCacheDuration of the client.
+//
+// The request is sent through the client like any other, so it counts towards
+// its rate limit.
+func (c *Client) HasOptiFineCape(name string) (has bool, err error) {
+	k := strings.ToLower(name)
+	if has, found := c.optifine.Get(k); found {
+		c.countCache(CacheOptiFine, "", true)
+		return has, nil
+	}
+	c.countCache(CacheOptiFine, "", false)
+	endpoint := OptiFineCapeURL(name)
+	resp, err := c.get(context.Background(), endpoint, name)
+	if err != nil {
+		return false, lookupError(endpoint, name, err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		has = true
+	case http.StatusNotFound:
+		has = false
+	default:
+		return false, lookupError(endpoint, name, statusError(resp))
+	}
+	if d := c.Config().OptiFineCacheDuration; d > 0 {
+		c.optifine.Set(k, has, d)
+	}
+	return has, nil
+}
+
+// HasOptiFineCape calls HasOptiFineCape on the default client.
+func HasOptiFineCape(name string) (has bool, err error) {
+	return defaultClient.HasOptiFineCape(name)
+}
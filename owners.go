@@ -0,0 +1,100 @@
+package mcaccutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A NameOwner is a player found to have used a name.
+type NameOwner struct {
+	// UUID is the undashed UUID of the player.
+	UUID string
+	// FirstSeen and LastSeen bound the time the player was seen with the
+	// name. They are the zero time if the source does not know.
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Sources lists the sources which reported the player.
+	Sources []string
+}
+
+// An OwnerSource is a service tracking which players have used which names
+// over time. Mojang offers no such endpoint since the name history was
+// retired, so sources are either local records, such as SQLHistory, or
+// third party services which keep their own.
+type OwnerSource interface {
+	// Name identifies the source in NameOwner.Sources.
+	Name() string
+	// PreviousOwners returns the players known to have used the name, in any
+	// order.
+	PreviousOwners(ctx context.Context, name string) ([]NameOwner, error)
+}
+
+// PreviousOwners asks the history of the client, if it is an OwnerSource,
+// and then each of Config.OwnerSources in turn, which players have used the
+// specified name, including its current owner. It is meant for
+// investigations such as spotting ban evasion, and only knows what the
+// sources have seen.
+//
+// Players reported by several sources are merged, with the widest range of
+// times. The result is ordered by FirstSeen, players with unknown times
+// last. If a source fails the others are still asked, and the error of the
+// first to fail is returned along with the owners found.
+func (c *Client) PreviousOwners(ctx context.Context, name string) (owners []NameOwner, err error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	cfg := c.Config()
+	var sources []OwnerSource
+	if s, ok := cfg.History.(OwnerSource); ok {
+		sources = append(sources, s)
+	}
+	sources = append(sources, cfg.OwnerSources...)
+	byUUID := make(map[string]*NameOwner)
+	for _, s := range sources {
+		found, serr := s.PreviousOwners(ctx, name)
+		if serr != nil {
+			if err == nil {
+				err = fmt.Errorf("mcaccutils: asking %s for the owners of %q: %w", s.Name(), name, serr)
+			}
+			continue
+		}
+		for _, o := range found {
+			o.UUID = strings.ToLower(strings.Replace(o.UUID, "-", "", -1))
+			m, ok := byUUID[o.UUID]
+			if !ok {
+				m = &NameOwner{UUID: o.UUID}
+				byUUID[o.UUID] = m
+			}
+			if !o.FirstSeen.IsZero() && (m.FirstSeen.IsZero() || o.FirstSeen.Before(m.FirstSeen)) {
+				m.FirstSeen = o.FirstSeen
+			}
+			if o.LastSeen.After(m.LastSeen) {
+				m.LastSeen = o.LastSeen
+			}
+			m.Sources = append(m.Sources, s.Name())
+		}
+	}
+	owners = make([]NameOwner, 0, len(byUUID))
+	for _, o := range byUUID {
+		owners = append(owners, *o)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		a, b := owners[i].FirstSeen, owners[j].FirstSeen
+		if a.IsZero() != b.IsZero() {
+			return b.IsZero()
+		}
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return owners[i].UUID < owners[j].UUID
+	})
+	return owners, err
+}
+
+// PreviousOwners calls PreviousOwners on the default client.
+func PreviousOwners(ctx context.Context, name string) (owners []NameOwner, err error) {
+	return defaultClient.PreviousOwners(ctx, name)
+}
@@ -0,0 +1,51 @@
+package mcaccutils
+
+import "strings"
+
+// PaperProfileType is the type key Bukkit writes alongside a serialized
+// PlayerProfile.
+const PaperProfileType = "PlayerProfile"
+
+// A PaperProfile is a profile in the format Paper and Spigot serialize their
+// PlayerProfile as, which plugins use to save profiles to disk. Every field
+// written by the servers is kept, so a file read into a PaperProfile is
+// written back unchanged.
+type PaperProfile struct {
+	// Type is the type key, "==", Bukkit adds when serializing to YAML. It
+	// is PaperProfileType, or empty if the plugin left it out.
+	Type string `json:"==,omitempty" yaml:"==,omitempty"`
+	// UniqueID is the dashed UUID of the player.
+	UniqueID string `json:"uniqueId,omitempty" yaml:"uniqueId,omitempty"`
+	// Name is the username of the player.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Properties holds the properties of the profile.
+	Properties []PaperProperty `json:"properties,omitempty" yaml:"properties,omitempty"`
+}
+
+// A PaperProperty is a property of a PaperProfile.
+type PaperProperty struct {
+	Name      string `json:"name" yaml:"name"`
+	Value     string `json:"value" yaml:"value"`
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+// PaperProfile returns p as a PaperProfile, with the type key set.
+func (p *Profile) PaperProfile() *PaperProfile {
+	pp := &PaperProfile{Type: PaperProfileType, UniqueID: p.UUID, Name: p.Name}
+	if dashed, err := DashUUID(p.UUID); err == nil {
+		pp.UniqueID = dashed
+	}
+	for _, prop := range p.Properties {
+		pp.Properties = append(pp.Properties, PaperProperty(prop))
+	}
+	return pp
+}
+
+// Profile returns pp as a Profile.
+func (pp *PaperProfile) Profile() *Profile {
+	p := &Profile{UUID: strings.ToLower(strings.Replace(pp.UniqueID, "-", "", -1)), Name: pp.Name}
+	for _, prop := range pp.Properties {
+		p.Properties = append(p.Properties, ProfileProperty(prop))
+	}
+	return p
+}
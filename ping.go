@@ -0,0 +1,80 @@
+package mcaccutils
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A PingResult is the result of checking that an upstream service can be
+// reached.
+type PingResult struct {
+	// URL is the base URL of the service.
+	URL string
+	// Latency is the time the service took to respond.
+	Latency time.Duration
+	// Err is the error which prevented a response, if any. Any HTTP response
+	// counts as success, as services often do not serve their root.
+	Err error
+}
+
+// unlimitedKey is the context key marking requests which should not wait for
+// or count towards the rate limit.
+type unlimitedKey struct{}
+
+// unlimited reports whether the request with context ctx skips the rate
+// limiter.
+func unlimited(ctx context.Context) bool {
+	skip, _ := ctx.Value(unlimitedKey{}).(bool)
+	return skip
+}
+
+// Ping sends a HEAD request to each upstream service configured for the
+// client, in parallel, and reports how long each took to respond. It is meant
+// for readiness checks, so the requests do not count towards the rate limit.
+// The results are in the order API, session server, authentication server,
+// leaving out services which share a URL.
+func (c *Client) Ping(ctx context.Context) []PingResult {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range []string{c.apiURL(), c.sessionServerURL(), c.authServerURL()} {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	ctx = context.WithValue(ctx, unlimitedKey{}, true)
+	results := make([]PingResult, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		results[i].URL = u
+		wg.Add(1)
+		go func(r *PingResult) {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, r.URL, nil)
+			if err != nil {
+				r.Err = err
+				return
+			}
+			start := time.Now()
+			resp, err := c.doer.Do(req)
+			r.Latency = time.Since(start)
+			if err != nil {
+				r.Err = err
+				return
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// Ping calls Ping on the default client.
+func Ping(ctx context.Context) []PingResult {
+	return defaultClient.Ping(ctx)
+}
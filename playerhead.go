@@ -0,0 +1,76 @@
+package mcaccutils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A HeadFormat is the syntax used to describe a player head item, which
+// changed between versions of Minecraft.
+type HeadFormat int
+
+const (
+	// HeadComponents is the item component syntax of Minecraft 1.20.5 and
+	// later.
+	HeadComponents HeadFormat = iota
+	// HeadNBT is the NBT syntax of Minecraft 1.16 to 1.20.4.
+	HeadNBT
+	// HeadLegacyNBT is the NBT syntax of Minecraft 1.13 to 1.15, which
+	// stores the UUID as a dashed string rather than an int array.
+	HeadLegacyNBT
+)
+
+// snbtString quotes s as an SNBT string.
+func snbtString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// HeadItem returns the item string of a player head showing the player, in
+// the format f, for use in commands such as /give. The head carries the
+// textures of the profile, so it shows the skin the player had when the
+// profile was fetched even if they change it later.
+func (p *Profile) HeadItem(f HeadFormat) (item string, err error) {
+	ints, err := UUIDToInts(p.UUID)
+	if err != nil {
+		return "", err
+	}
+	id := fmt.Sprintf("[I;%d,%d,%d,%d]", ints[0], ints[1], ints[2], ints[3])
+	var textures string
+	for _, prop := range p.Properties {
+		if prop.Name == "textures" {
+			textures = prop.Value
+		}
+	}
+	switch f {
+	case HeadComponents:
+		props := ""
+		if textures != "" {
+			props = fmt.Sprintf(",properties:[{name:\"textures\",value:%s}]", snbtString(textures))
+		}
+		return fmt.Sprintf("minecraft:player_head[minecraft:profile={id:%s,name:%s%s}]", id, snbtString(p.Name), props), nil
+	case HeadNBT, HeadLegacyNBT:
+		if f == HeadLegacyNBT {
+			dashed, err := DashUUID(p.UUID)
+			if err != nil {
+				return "", err
+			}
+			id = snbtString(dashed)
+		}
+		props := ""
+		if textures != "" {
+			props = fmt.Sprintf(",Properties:{textures:[{Value:%s}]}", snbtString(textures))
+		}
+		return fmt.Sprintf("minecraft:player_head{SkullOwner:{Id:%s,Name:%s%s}}", id, snbtString(p.Name), props), nil
+	}
+	return "", fmt.Errorf("mcaccutils: unknown head format %d", f)
+}
+
+// GiveCommand returns a /give command which gives target, such as @p or a
+// username, the head of the player as returned by HeadItem.
+func (p *Profile) GiveCommand(target string, f HeadFormat) (cmd string, err error) {
+	item, err := p.HeadItem(f)
+	if err != nil {
+		return "", err
+	}
+	return "/give " + target + " " + item, nil
+}
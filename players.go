@@ -0,0 +1,158 @@
+package mcaccutils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A StoredPlayer is a resolved identity kept in a PlayerStore.
+type StoredPlayer struct {
+	// UUID is the undashed UUID of the player.
+	UUID string
+	// Name is the name of the player when they were resolved.
+	Name string
+	// Resolved is when the player was last resolved.
+	Resolved time.Time
+}
+
+// A PlayerStore keeps resolved players in a database of the program's own,
+// such as the one a plugin or service already uses for its players, so their
+// identities outlive the cache of the client. The client saves every player
+// it resolves to Config.PlayerStore, and LoadPlayer decides when a saved
+// player is too old to be trusted.
+type PlayerStore interface {
+	// Save stores p under its UUID, replacing any player stored before.
+	Save(ctx context.Context, p StoredPlayer) error
+	// Load returns the player stored under the undashed UUID uuid. It
+	// reports found as false if there is none.
+	Load(ctx context.Context, uuid string) (p StoredPlayer, found bool, err error)
+}
+
+// savePlayer saves p to the player store of the client, if it has one.
+// Errors are ignored, as the player is still cached.
+func (c *Client) savePlayer(p *playerCacheData) {
+	ps := c.Config().PlayerStore
+	if ps == nil {
+		return
+	}
+	ps.Save(context.Background(), StoredPlayer{UUID: p.UUID, Name: p.Username, Resolved: c.now()})
+}
+
+// LoadPlayer returns the player with the specified UUID, which may be dashed
+// or undashed, from Config.PlayerStore if it was resolved less than maxAge
+// ago, and otherwise looks the player up again as GetNameContext does, saving
+// the result to the store. If the lookup fails, the stale player is
+// returned along with the error, if there is one, so callers can choose to
+// fall back to it.
+func (c *Client) LoadPlayer(ctx context.Context, uuid string, maxAge time.Duration) (p StoredPlayer, err error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return StoredPlayer{}, err
+	}
+	uuid = formatUUID(b)
+	if ps := c.Config().PlayerStore; ps != nil {
+		var found bool
+		p, found, err = ps.Load(ctx, uuid)
+		if err != nil {
+			return StoredPlayer{}, fmt.Errorf("mcaccutils: loading %s from player store: %w", uuid, err)
+		}
+		if found && c.now().Sub(p.Resolved) < maxAge {
+			return p, nil
+		}
+	}
+	name, err := c.GetNameContext(ctx, uuid)
+	if err != nil {
+		return p, err
+	}
+	// The name may have come from the cache rather than the API, in which
+	// case it was not saved, but it is as fresh as the cache allows.
+	c.savePlayer(&playerCacheData{UUID: uuid, Username: name})
+	return StoredPlayer{UUID: uuid, Name: name, Resolved: c.now()}, nil
+}
+
+// LoadPlayer calls LoadPlayer on the default client.
+func LoadPlayer(ctx context.Context, uuid string, maxAge time.Duration) (p StoredPlayer, err error) {
+	return defaultClient.LoadPlayer(ctx, uuid, maxAge)
+}
+
+// MemoryPlayerStore is a PlayerStore which keeps players in memory, for tests
+// and programs which persist them some other way. The zero value is ready to
+// use.
+type MemoryPlayerStore struct {
+	mu      sync.Mutex
+	players map[string]StoredPlayer
+}
+
+// Save implements PlayerStore.
+func (s *MemoryPlayerStore) Save(ctx context.Context, p StoredPlayer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.players == nil {
+		s.players = make(map[string]StoredPlayer)
+	}
+	s.players[strings.ToLower(p.UUID)] = p
+	return nil
+}
+
+// Load implements PlayerStore.
+func (s *MemoryPlayerStore) Load(ctx context.Context, uuid string) (p StoredPlayer, found bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, found = s.players[strings.ToLower(uuid)]
+	return p, found, nil
+}
+
+// SQLPlayerStore is a PlayerStore which keeps players in the
+// mcaccutils_players table of a SQL database, through database/sql. The
+// caller is responsible for importing a driver.
+//
+// The table can be joined with the tables of the program: uuid holds the
+// undashed UUID, name the name, and resolved the time the player was resolved
+// as a Unix timestamp.
+type SQLPlayerStore struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLPlayerStore returns a SQLPlayerStore using the database db, which is
+// of the specified dialect. MigrateSQL must have been called on the database
+// before the store is used.
+func NewSQLPlayerStore(db *sql.DB, d Dialect) *SQLPlayerStore {
+	return &SQLPlayerStore{db: db, dialect: d}
+}
+
+// Save implements PlayerStore.
+func (s *SQLPlayerStore) Save(ctx context.Context, p StoredPlayer) error {
+	ph := s.dialect.Placeholder
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO mcaccutils_players (uuid, name, resolved) VALUES ("+ph(1)+", "+ph(2)+", "+ph(3)+") "+
+			"ON CONFLICT (uuid) DO UPDATE SET name = excluded.name, resolved = excluded.resolved",
+		p.UUID, p.Name, p.Resolved.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: saving %s to SQL player store: %w", p.UUID, err)
+	}
+	return nil
+}
+
+// Load implements PlayerStore.
+func (s *SQLPlayerStore) Load(ctx context.Context, uuid string) (p StoredPlayer, found bool, err error) {
+	var resolved int64
+	err = s.db.QueryRowContext(ctx,
+		"SELECT uuid, name, resolved FROM mcaccutils_players WHERE uuid = "+s.dialect.Placeholder(1),
+		uuid,
+	).Scan(&p.UUID, &p.Name, &resolved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return StoredPlayer{}, false, nil
+	}
+	if err != nil {
+		return StoredPlayer{}, false, fmt.Errorf("mcaccutils: loading %s from SQL player store: %w", uuid, err)
+	}
+	p.Resolved = time.Unix(resolved, 0)
+	return p, true, nil
+}
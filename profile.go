@@ -0,0 +1,173 @@
+package mcaccutils
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// A Profile is the public profile of a player, as served by the Mojang
+// session server.
+type Profile struct {
+	// UUID is the UUID of the player, without dashes.
+	UUID string `json:"id"`
+	// Name is the current username of the player.
+	Name string `json:"name"`
+	// Properties holds the properties of the profile, such as the textures
+	// property describing the skin and cape of the player.
+	Properties []ProfileProperty `json:"properties"`
+	// Actions lists the actions Mojang requires the player to take before
+	// they may play online, if any.
+	Actions []ProfileAction `json:"profileActions,omitempty"`
+}
+
+// A ProfileAction is an action Mojang requires a player to take, usually as a
+// result of moderation.
+type ProfileAction string
+
+const (
+	// ActionForcedNameChange means the player must choose a new name, as
+	// their current one breaks the rules.
+	ActionForcedNameChange ProfileAction = "FORCED_NAME_CHANGE"
+	// ActionUsingBannedSkin means the player must change their skin, as
+	// their current one breaks the rules.
+	ActionUsingBannedSkin ProfileAction = "USING_BANNED_SKIN"
+)
+
+// HasAction reports whether the player is required to take the action a.
+func (p *Profile) HasAction(a ProfileAction) bool {
+	for _, pa := range p.Actions {
+		if pa == a {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActionRequired reports whether the player is required to take any action,
+// including ones unknown to this package.
+func (p *Profile) IsActionRequired() bool {
+	return len(p.Actions) > 0
+}
+
+// A ProfileProperty is a single property of a Profile. Its value is usually
+// base64 encoded JSON.
+type ProfileProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Signature is the base64 encoded signature of Value. It is only present
+	// if the profile was requested with signatures.
+	Signature string `json:"signature,omitempty"`
+}
+
+// GetProfile returns the profile of the player with the specified UUID using a
+// single request to the session server.
+//
+// The result of this function is cached for the ProfileCacheDuration of the
+// client, which is much shorter than the cache of names and UUIDs, so it
+// should be used with caution so as to avoid running into the Mojang rate
+// limit.
+func (c *Client) GetProfile(uuid string) (profile *Profile, err error) {
+	return c.GetProfileContext(context.Background(), uuid)
+}
+
+// GetProfileContext is like GetProfile, but gives up when ctx is done.
+func (c *Client) GetProfileContext(ctx context.Context, uuid string) (profile *Profile, err error) {
+	return c.getProfile(ctx, uuid, false)
+}
+
+// GetSignedProfile is like GetProfile, but the properties of the profile
+// carry the signatures of the session server, as needed by servers which
+// pass the textures of players on to game clients. Signed profiles are
+// cached separately from unsigned ones.
+func (c *Client) GetSignedProfile(uuid string) (profile *Profile, err error) {
+	return c.GetSignedProfileContext(context.Background(), uuid)
+}
+
+// GetSignedProfileContext is like GetSignedProfile, but gives up when ctx is
+// done.
+func (c *Client) GetSignedProfileContext(ctx context.Context, uuid string) (profile *Profile, err error) {
+	return c.getProfile(ctx, uuid, true)
+}
+
+// getProfile fetches the profile of the player with the specified UUID, with
+// signed properties if signed is set.
+func (c *Client) getProfile(ctx context.Context, uuid string, signed bool) (profile *Profile, err error) {
+	defer func() { c.metrics.countError(err) }()
+	if err := c.checkEndpoint(FeatureProfile, c.sessionServerURL()); err != nil {
+		return nil, err
+	}
+	uuid = strings.ToLower(strings.Replace(uuid, "-", "", -1))
+	key := uuid
+	if signed {
+		key += "/signed"
+	}
+	if p, found := c.profiles.Get(key); found {
+		c.countCache(CacheProfiles, "", true)
+		return p.clone(), nil
+	}
+	c.countCache(CacheProfiles, "", false)
+	endpoint := c.sessionServerURL() + "/session/minecraft/profile/" + uuid
+	reqURL := endpoint
+	if signed {
+		reqURL += "?unsigned=false"
+	}
+	resp, err := c.get(ctx, reqURL, uuid)
+	if err != nil {
+		return nil, lookupError(endpoint, uuid, err)
+	}
+	defer resp.Body.Close()
+	// Read out the body.
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, lookupError(endpoint, uuid, err)
+	}
+	if err := c.checkRetired(FeatureProfile, c.sessionServerURL(), resp); err != nil {
+		return nil, err
+	}
+	// Unknown UUIDs get an empty response.
+	if resp.StatusCode == http.StatusNoContent || len(body) == 0 {
+		return nil, ErrPlayerNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, uuid, statusError(resp))
+	}
+	// Decode the JSON
+	p := &Profile{}
+	if err := c.decodeJSON(endpoint, body, p); err != nil {
+		return nil, err
+	}
+	if p.UUID == "" {
+		return nil, ErrPlayerNotFound
+	}
+	if d := c.Config().ProfileCacheDuration; d > 0 {
+		c.profiles.Set(key, p.clone(), d)
+	}
+	return p, nil
+}
+
+// clone returns a copy of p which shares no memory with it, so cached
+// profiles are not changed by callers.
+func (p *Profile) clone() *Profile {
+	np := *p
+	np.Properties = append([]ProfileProperty(nil), p.Properties...)
+	np.Actions = append([]ProfileAction(nil), p.Actions...)
+	return &np
+}
+
+// GetProfile calls GetProfile on the default client.
+func GetProfile(uuid string) (*Profile, error) {
+	return defaultClient.GetProfile(uuid)
+}
+
+// GetSignedProfile calls GetSignedProfile on the default client.
+func GetSignedProfile(uuid string) (*Profile, error) {
+	return defaultClient.GetSignedProfile(uuid)
+}
+
+// GetSignedProfileContext calls GetSignedProfileContext on the default
+// client.
+func GetSignedProfileContext(ctx context.Context, uuid string) (*Profile, error) {
+	return defaultClient.GetSignedProfileContext(ctx, uuid)
+}
@@ -0,0 +1,91 @@
+package mcaccutils
+
+import (
+	"errors"
+	"strings"
+)
+
+// A Provider resolves players. Client is a Provider for the Mojang API, and
+// mirrors or third party services can be used wherever a Provider is accepted
+// by implementing it.
+type Provider interface {
+	// GetUUID returns the undashed UUID and the case corrected username of
+	// the player with the given name.
+	GetUUID(name string) (uuid string, correctName string, err error)
+	// GetProfile returns the profile of the player with the given UUID.
+	GetProfile(uuid string) (*Profile, error)
+}
+
+// A Divergence describes a lookup for which the shadow provider of a Client
+// returned a different result to the client itself.
+type Divergence struct {
+	// Query is the username or UUID which was looked up.
+	Query string
+
+	// UUID, Name and Err are the result of the lookup by the client.
+	UUID string
+	Name string
+	Err  error
+
+	// ShadowUUID, ShadowName and ShadowErr are the result of the lookup by
+	// the shadow provider.
+	ShadowUUID string
+	ShadowName string
+	ShadowErr  error
+}
+
+// shadowUUID repeats the lookup of the name n with the shadow provider, if
+// there is one, and reports any divergence from the result p, err.
+func (c *Client) shadowUUID(n string, p *playerCacheData, err error) {
+	cfg := c.Config()
+	if cfg.Shadow == nil {
+		return
+	}
+	go func() {
+		d := Divergence{Query: n, Err: err}
+		d.ShadowUUID, d.ShadowName, d.ShadowErr = cfg.Shadow.GetUUID(n)
+		if p != nil {
+			d.UUID, d.Name = p.UUID, p.Username
+		}
+		c.compareShadow(cfg, d)
+	}()
+}
+
+// shadowName repeats the lookup of the UUID uuid with the shadow provider, if
+// there is one, and reports any divergence from the result p, err.
+func (c *Client) shadowName(uuid string, p *playerCacheData, err error) {
+	cfg := c.Config()
+	if cfg.Shadow == nil {
+		return
+	}
+	go func() {
+		d := Divergence{Query: uuid, Err: err}
+		profile, serr := cfg.Shadow.GetProfile(uuid)
+		if serr == nil {
+			d.ShadowUUID, d.ShadowName = profile.UUID, profile.Name
+		}
+		d.ShadowErr = serr
+		if p != nil {
+			d.UUID, d.Name = p.UUID, p.Username
+		}
+		c.compareShadow(cfg, d)
+	}()
+}
+
+// compareShadow passes d to the divergence callback unless both lookups
+// agreed.
+func (c *Client) compareShadow(cfg Config, d Divergence) {
+	if cfg.OnDivergence == nil {
+		return
+	}
+	bothNotFound := errors.Is(d.Err, ErrPlayerNotFound) && errors.Is(d.ShadowErr, ErrPlayerNotFound)
+	if bothNotFound {
+		return
+	}
+	sameResult := d.Err == nil && d.ShadowErr == nil &&
+		d.UUID == strings.Replace(d.ShadowUUID, "-", "", -1) && d.Name == d.ShadowName
+	if sameResult {
+		return
+	}
+	cfg.OnDivergence(d)
+}
@@ -0,0 +1,33 @@
+// Package providers sets up clients for popular third party skin systems,
+// which offline mode communities use in place of Mojang accounts. The
+// clients are ordinary mcaccutils Clients, so they implement
+// mcaccutils.Provider, and their profiles and skins work with the rest of the
+// module, such as the skin renderers.
+//
+// To change the configuration of a client, pass the root of the service to
+// mcaccutils.AuthlibInjectorConfig and adjust the result:
+//
+//	cfg := mcaccutils.AuthlibInjectorConfig(providers.ElyByRoot)
+//	cfg.CacheDuration = time.Hour
+//	elyby := mcaccutils.NewClient(cfg)
+package providers
+
+import "github.com/bearbin/go-mcaccutils"
+
+// The roots of the authlib-injector APIs of the supported services.
+const (
+	ElyByRoot      = "https://account.ely.by/api/authlib-injector"
+	LittleSkinRoot = "https://littleskin.cn/api/yggdrasil"
+)
+
+// ElyBy returns a client for Ely.by accounts, with the recommended
+// configuration.
+func ElyBy() *mcaccutils.Client {
+	return mcaccutils.NewClient(mcaccutils.AuthlibInjectorConfig(ElyByRoot))
+}
+
+// LittleSkin returns a client for LittleSkin accounts, with the recommended
+// configuration.
+func LittleSkin() *mcaccutils.Client {
+	return mcaccutils.NewClient(mcaccutils.AuthlibInjectorConfig(LittleSkinRoot))
+}
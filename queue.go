@@ -0,0 +1,183 @@
+package mcaccutils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SQLQueue is a durable queue of names to look up, kept in the
+// mcaccutils_queue table of a SQL database through database/sql. Bulk jobs
+// which add their names to a queue and resolve them with Run can be stopped
+// at any point, and pick up where they left off when run again, without
+// looking up any name twice.
+//
+// Each queue belongs to a job, so one database can hold the queues of several
+// jobs.
+type SQLQueue struct {
+	db      *sql.DB
+	dialect Dialect
+	job     string
+}
+
+// NewSQLQueue returns the queue of the named job in the database db, which is
+// of the specified dialect. MigrateSQL must have been called on the database
+// before the queue is used.
+func NewSQLQueue(db *sql.DB, d Dialect, job string) *SQLQueue {
+	return &SQLQueue{db: db, dialect: d, job: job}
+}
+
+// Add adds names to the queue. Names already in the queue, ignoring case, are
+// skipped, so a job may add all its names every time it starts.
+func (q *SQLQueue) Add(names ...string) error {
+	p := q.dialect.Placeholder
+	tx, err := q.db.Begin()
+	if err != nil {
+		return fmt.Errorf("mcaccutils: adding to queue %q: %w", q.job, err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(
+		"INSERT INTO mcaccutils_queue (job, query, name_key, done, uuid, name, not_found) " +
+			"VALUES (" + p(1) + ", " + p(2) + ", " + p(3) + ", " + p(4) + ", '', '', " + p(5) + ") " +
+			"ON CONFLICT (job, name_key) DO NOTHING",
+	)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: adding to queue %q: %w", q.job, err)
+	}
+	defer stmt.Close()
+	for _, n := range names {
+		if _, err := stmt.Exec(q.job, n, strings.ToLower(n), false, false); err != nil {
+			return fmt.Errorf("mcaccutils: adding %q to queue %q: %w", n, q.job, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("mcaccutils: adding to queue %q: %w", q.job, err)
+	}
+	return nil
+}
+
+// Pending returns the number of names in the queue which have not been
+// resolved yet.
+func (q *SQLQueue) Pending() (n int, err error) {
+	p := q.dialect.Placeholder
+	err = q.db.QueryRow(
+		"SELECT COUNT(*) FROM mcaccutils_queue WHERE job = "+p(1)+" AND NOT done", q.job,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("mcaccutils: counting queue %q: %w", q.job, err)
+	}
+	return n, nil
+}
+
+// pending returns up to limit unresolved names from the queue.
+func (q *SQLQueue) pending(limit int) ([]string, error) {
+	p := q.dialect.Placeholder
+	rows, err := q.db.Query(
+		"SELECT query FROM mcaccutils_queue WHERE job = "+p(1)+" AND NOT done ORDER BY name_key LIMIT "+p(2),
+		q.job, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading queue %q: %w", q.job, err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("mcaccutils: reading queue %q: %w", q.job, err)
+		}
+		names = append(names, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading queue %q: %w", q.job, err)
+	}
+	return names, nil
+}
+
+// Run resolves the pending names in the queue with the client c, a batch at
+// a time, saving each result as soon as it arrives. Names no player can have
+// are resolved without a request, and count as resolved like names with no
+// player. It returns once every name has been resolved, when ctx is done,
+// with an error matching ErrCanceled, or when a lookup fails for another
+// reason, such as the rate limit or the network; the names which were not
+// resolved stay in the queue for the next run.
+func (q *SQLQueue) Run(ctx context.Context, c *Client) error {
+	p := q.dialect.Placeholder
+	update := "UPDATE mcaccutils_queue SET done = " + p(1) + ", uuid = " + p(2) + ", name = " + p(3) +
+		", not_found = " + p(4) + ", invalid = " + p(5) + " WHERE job = " + p(6) + " AND name_key = " + p(7)
+	for {
+		if err := canceled(ctx); err != nil {
+			return err
+		}
+		names, err := q.pending(c.batchSize())
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		var failed error
+		for _, r := range c.GetUUIDsContext(ctx, names) {
+			// The reason a name is invalid is kept, so Results can return
+			// the same error.
+			var (
+				invalid *InvalidNameError
+				reason  string
+			)
+			if errors.As(r.Err, &invalid) {
+				reason = invalid.Reason
+			}
+			notFound := invalid != nil || errors.Is(r.Err, ErrPlayerNotFound)
+			if r.Err != nil && !notFound {
+				failed = r.Err
+				continue
+			}
+			_, err := q.db.Exec(update, true, r.UUID, r.Name, notFound, reason, q.job, strings.ToLower(r.Query))
+			if err != nil {
+				return fmt.Errorf("mcaccutils: saving result for %q in queue %q: %w", r.Query, q.job, err)
+			}
+		}
+		if failed != nil {
+			return failed
+		}
+	}
+}
+
+// Results returns the result of every resolved name in the queue, ordered by
+// name. Names with no player have ErrPlayerNotFound as their error, and
+// names no player can have an *InvalidNameError.
+func (q *SQLQueue) Results() ([]UUIDResult, error) {
+	p := q.dialect.Placeholder
+	rows, err := q.db.Query(
+		"SELECT query, uuid, name, not_found, invalid FROM mcaccutils_queue WHERE job = "+p(1)+" AND done ORDER BY name_key",
+		q.job,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading results of queue %q: %w", q.job, err)
+	}
+	defer rows.Close()
+	var results []UUIDResult
+	for rows.Next() {
+		var (
+			r        UUIDResult
+			notFound bool
+			invalid  string
+		)
+		if err := rows.Scan(&r.Query, &r.UUID, &r.Name, &notFound, &invalid); err != nil {
+			return nil, fmt.Errorf("mcaccutils: reading results of queue %q: %w", q.job, err)
+		}
+		switch {
+		case invalid != "":
+			r.Err = &InvalidNameError{Name: r.Query, Reason: invalid}
+		case notFound:
+			r.Err = ErrPlayerNotFound
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading results of queue %q: %w", q.job, err)
+	}
+	return results, nil
+}
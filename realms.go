@@ -0,0 +1,305 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RealmsURL is the base URL of the Minecraft Realms API, used when
+// Config.RealmsURL is empty.
+const RealmsURL = "https://pc.realms.minecraft.net"
+
+// DefaultRealmsVersion is the game version reported to Realms when
+// Config.RealmsVersion is empty.
+const DefaultRealmsVersion = "1.21.1"
+
+// A RealmWorld is a Minecraft Realms world, as seen by a player who owns it
+// or has been invited to it.
+type RealmWorld struct {
+	// ID identifies the realm in later requests.
+	ID int64 `json:"id"`
+	// Owner and OwnerUUID are the name and undashed UUID of the player who
+	// pays for the realm.
+	Owner     string `json:"owner"`
+	OwnerUUID string `json:"ownerUUID"`
+	// Name and MOTD are the name and description of the realm.
+	Name string `json:"name"`
+	MOTD string `json:"motd"`
+	// State is "OPEN", "CLOSED" or "UNINITIALIZED".
+	State string `json:"state"`
+	// DaysLeft is the number of days left of the subscription, and Expired
+	// reports whether it has run out.
+	DaysLeft int  `json:"daysLeft"`
+	Expired  bool `json:"expired"`
+	// WorldType is "NORMAL", "MINIGAME", "ADVENTUREMAP" and so on.
+	WorldType string `json:"worldType"`
+	// MaxPlayers is the number of players the realm can hold at once.
+	MaxPlayers int `json:"maxPlayers"`
+	// Players lists the players invited to the realm. The Realms API only
+	// fills it in for realms the player owns, or when fetched with Realm.
+	Players []RealmPlayer `json:"players"`
+}
+
+// A RealmPlayer is a player invited to a realm.
+type RealmPlayer struct {
+	// Name and UUID are the name and undashed UUID of the player.
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+	// Operator reports whether the player is an operator of the realm.
+	Operator bool `json:"operator"`
+	// Accepted reports whether the player has accepted the invite.
+	Accepted bool `json:"accepted"`
+	// Online reports whether the player is playing in the realm.
+	Online bool `json:"online"`
+}
+
+// A RealmInvite is a pending invite to another player's realm.
+type RealmInvite struct {
+	// ID identifies the invite to AcceptRealmInvite and RejectRealmInvite.
+	ID string
+	// WorldName and WorldDescription describe the realm.
+	WorldName        string
+	WorldDescription string
+	// OwnerName and OwnerUUID are the name and undashed UUID of the player
+	// who sent the invite.
+	OwnerName string
+	OwnerUUID string
+	// Date is when the invite was sent.
+	Date time.Time
+}
+
+type realmsInvite struct {
+	ID               string `json:"invitationId"`
+	WorldName        string `json:"worldName"`
+	WorldDescription string `json:"worldDescription"`
+	OwnerName        string `json:"worldOwnerName"`
+	OwnerUUID        string `json:"worldOwnerUuid"`
+	Date             int64  `json:"date"`
+}
+
+// Realms returns the realms the player logged in to the session s owns or has
+// joined. The session must have a selected profile, as Realms identifies the
+// player by it.
+func (c *Client) Realms(ctx context.Context, s *Session) ([]RealmWorld, error) {
+	var dec struct {
+		Servers []RealmWorld `json:"servers"`
+	}
+	if err := c.realmsRequest(ctx, s, http.MethodGet, "/worlds", nil, &dec); err != nil {
+		return nil, err
+	}
+	if dec.Servers == nil {
+		dec.Servers = []RealmWorld{}
+	}
+	return dec.Servers, nil
+}
+
+// Realm returns the realm with the specified ID, including its players.
+func (c *Client) Realm(ctx context.Context, s *Session, id int64) (*RealmWorld, error) {
+	var r RealmWorld
+	if err := c.realmsRequest(ctx, s, http.MethodGet, "/worlds/"+strconv.FormatInt(id, 10), nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// RealmAddress returns the address of the server running the realm with the
+// specified ID, as "host:port", starting the realm if it is not running.
+// Realms answers with a *AuthError with status 503 Service Unavailable while
+// the realm starts, which callers should retry after a few seconds.
+func (c *Client) RealmAddress(ctx context.Context, s *Session, id int64) (string, error) {
+	var dec struct {
+		Address string `json:"address"`
+	}
+	path := "/worlds/v1/" + strconv.FormatInt(id, 10) + "/join/pc"
+	if err := c.realmsRequest(ctx, s, http.MethodGet, path, nil, &dec); err != nil {
+		return "", err
+	}
+	return dec.Address, nil
+}
+
+// RealmInvites returns the pending invites of the player logged in to the
+// session s.
+func (c *Client) RealmInvites(ctx context.Context, s *Session) ([]RealmInvite, error) {
+	var dec struct {
+		Invites []realmsInvite `json:"invites"`
+	}
+	if err := c.realmsRequest(ctx, s, http.MethodGet, "/invites/pending", nil, &dec); err != nil {
+		return nil, err
+	}
+	invites := make([]RealmInvite, 0, len(dec.Invites))
+	for _, i := range dec.Invites {
+		invites = append(invites, RealmInvite{
+			ID:               i.ID,
+			WorldName:        i.WorldName,
+			WorldDescription: i.WorldDescription,
+			OwnerName:        i.OwnerName,
+			OwnerUUID:        strings.Replace(i.OwnerUUID, "-", "", -1),
+			Date:             time.Unix(0, i.Date*int64(time.Millisecond)),
+		})
+	}
+	return invites, nil
+}
+
+// AcceptRealmInvite accepts the pending invite with the specified ID.
+func (c *Client) AcceptRealmInvite(ctx context.Context, s *Session, id string) error {
+	return c.realmsRequest(ctx, s, http.MethodPut, "/invites/accept/"+url.PathEscape(id), nil, nil)
+}
+
+// RejectRealmInvite rejects the pending invite with the specified ID.
+func (c *Client) RejectRealmInvite(ctx context.Context, s *Session, id string) error {
+	return c.realmsRequest(ctx, s, http.MethodPut, "/invites/reject/"+url.PathEscape(id), nil, nil)
+}
+
+// InviteToRealm invites the player with the specified username to the realm
+// with the specified ID, which the player logged in to the session s must
+// own. The UUID of the player is resolved like GetUUID does, so it comes from
+// the cache if it is there.
+func (c *Client) InviteToRealm(ctx context.Context, s *Session, id int64, name string) error {
+	uuid, name, err := c.GetUUIDContext(ctx, name)
+	if err != nil {
+		return err
+	}
+	body := struct {
+		Name string `json:"name"`
+		UUID string `json:"uuid"`
+	}{name, uuid}
+	return c.realmsRequest(ctx, s, http.MethodPost, "/invites/"+strconv.FormatInt(id, 10), body, nil)
+}
+
+// RemoveFromRealm removes the player with the specified UUID, with or
+// without dashes, from the realm with the specified ID, which the player
+// logged in to the session s must own.
+func (c *Client) RemoveFromRealm(ctx context.Context, s *Session, id int64, uuid string) error {
+	uuid = strings.ToLower(strings.Replace(uuid, "-", "", -1))
+	if _, err := parseUUID(uuid); err != nil {
+		return err
+	}
+	path := "/invites/" + strconv.FormatInt(id, 10) + "/invite/" + uuid
+	return c.realmsRequest(ctx, s, http.MethodDelete, path, nil, nil)
+}
+
+// Realms calls Realms on the default client.
+func Realms(ctx context.Context, s *Session) ([]RealmWorld, error) {
+	return defaultClient.Realms(ctx, s)
+}
+
+// Realm calls Realm on the default client.
+func Realm(ctx context.Context, s *Session, id int64) (*RealmWorld, error) {
+	return defaultClient.Realm(ctx, s, id)
+}
+
+// RealmAddress calls RealmAddress on the default client.
+func RealmAddress(ctx context.Context, s *Session, id int64) (string, error) {
+	return defaultClient.RealmAddress(ctx, s, id)
+}
+
+// RealmInvites calls RealmInvites on the default client.
+func RealmInvites(ctx context.Context, s *Session) ([]RealmInvite, error) {
+	return defaultClient.RealmInvites(ctx, s)
+}
+
+// AcceptRealmInvite calls AcceptRealmInvite on the default client.
+func AcceptRealmInvite(ctx context.Context, s *Session, id string) error {
+	return defaultClient.AcceptRealmInvite(ctx, s, id)
+}
+
+// RejectRealmInvite calls RejectRealmInvite on the default client.
+func RejectRealmInvite(ctx context.Context, s *Session, id string) error {
+	return defaultClient.RejectRealmInvite(ctx, s, id)
+}
+
+// InviteToRealm calls InviteToRealm on the default client.
+func InviteToRealm(ctx context.Context, s *Session, id int64, name string) error {
+	return defaultClient.InviteToRealm(ctx, s, id, name)
+}
+
+// RemoveFromRealm calls RemoveFromRealm on the default client.
+func RemoveFromRealm(ctx context.Context, s *Session, id int64, uuid string) error {
+	return defaultClient.RemoveFromRealm(ctx, s, id, uuid)
+}
+
+// realmsRequest sends a request to the Realms API as the player logged in to
+// the session s, with v as the JSON body if it is not nil, and decodes the
+// response into resp if it is not nil. Rejected requests are returned as an
+// *AuthError.
+func (c *Client) realmsRequest(ctx context.Context, s *Session, method, path string, v, resp interface{}) error {
+	if s == nil || s.SelectedProfile == nil {
+		return errors.New("mcaccutils: Realms needs a session with a selected profile")
+	}
+	endpoint := c.realmsURL() + path
+	var body io.Reader
+	if v != nil {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if v != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	version := c.Config().RealmsVersion
+	if version == "" {
+		version = DefaultRealmsVersion
+	}
+	// Realms takes the session as cookies rather than a bearer token.
+	req.Header.Set("Cookie", fmt.Sprintf("sid=token:%s:%s;user=%s;version=%s",
+		s.AccessToken, s.SelectedProfile.UUID, s.SelectedProfile.Name, version))
+	r, err := c.doer.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return canceled(ctx)
+		}
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		var e struct {
+			Code    int    `json:"errorCode"`
+			Message string `json:"errorMsg"`
+		}
+		authErr := newAuthError(r)
+		// The body is not always JSON, in which case the status is enough.
+		if json.Unmarshal(b, &e) == nil {
+			authErr.Message = e.Message
+			if e.Code != 0 {
+				authErr.Type = strconv.Itoa(e.Code)
+			}
+		}
+		return authErr
+	}
+	if resp == nil || len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, resp); err != nil {
+		return fmt.Errorf("mcaccutils: calling %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// realmsURL returns the base URL of the Realms API.
+func (c *Client) realmsURL() string {
+	if u := c.Config().RealmsURL; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	return RealmsURL
+}
@@ -0,0 +1,117 @@
+package mcaccutils
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// The variables below configure the background refresher of the client used
+// by the package level functions.
+var (
+	// RefreshThreshold is the number of cache hits after which an entry is
+	// considered hot.
+	//
+	// Deprecated: Use Config.RefreshThreshold with NewClient instead.
+	RefreshThreshold int64 = 10
+
+	// RefreshWindow is how long before its expiry a hot entry becomes
+	// eligible for a background refresh.
+	//
+	// Deprecated: Use Config.RefreshWindow with NewClient instead.
+	RefreshWindow = 30 * time.Minute
+
+	// RefreshInterval is the minimum time between two requests made by the
+	// background refresher.
+	//
+	// Deprecated: Use Config.RefreshInterval with NewClient instead.
+	RefreshInterval = 5 * time.Second
+)
+
+// StartRefresher starts a goroutine which looks up hot cache entries again
+// shortly before they expire, so frequently requested players are always
+// served from the cache. At most one entry is refreshed per refresh interval.
+//
+// The returned function stops the refresher, abandoning any refresh in
+// progress.
+func (c *Client) StartRefresher() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		ticker := time.NewTicker(c.refreshInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if p := c.nextRefresh(); p != nil {
+					c.refresh(ctx, p)
+				}
+			}
+		}
+	}()
+	return cancel
+}
+
+// StartRefresher calls StartRefresher on the default client.
+func StartRefresher() (stop func()) {
+	return defaultClient.StartRefresher()
+}
+
+// refreshInterval returns the minimum time between two background refreshes.
+func (c *Client) refreshInterval() time.Duration {
+	if d := c.Config().RefreshInterval; d > 0 {
+		return d
+	}
+	return 5 * time.Second
+}
+
+// nextRefresh returns the hot entry closest to expiry which is inside the
+// refresh window, or nil if there is nothing to refresh.
+func (c *Client) nextRefresh() *playerCacheData {
+	var (
+		next    *playerCacheData
+		nextExp time.Time
+	)
+	cfg := c.Config()
+	deadline := c.now().Add(cfg.RefreshWindow)
+	for k, item := range c.cache.Items() {
+		p := item.Value
+		// Every player is stored twice, so only look at the UUID entries.
+		if k != p.UUID {
+			continue
+		}
+		if atomic.LoadInt64(&p.hits) < cfg.RefreshThreshold || item.Expires.After(deadline) {
+			continue
+		}
+		if next == nil || item.Expires.Before(nextExp) {
+			next, nextExp = p, item.Expires
+		}
+	}
+	return next
+}
+
+// refresh fetches p from the API again and replaces its cache entries.
+func (c *Client) refresh(ctx context.Context, p *playerCacheData) {
+	np, err := c.fetchName(ctx, p.UUID)
+	if ctx.Err() != nil {
+		// The refresher was stopped, which says nothing about the player.
+		return
+	}
+	c.record(p.UUID, np, err)
+	if err != nil {
+		// Leave the entry to expire normally rather than retrying it on every
+		// tick.
+		atomic.StoreInt64(&p.hits, 0)
+		return
+	}
+	if !strings.EqualFold(np.Username, p.Username) {
+		// The player has been renamed, so the old name no longer refers to
+		// them.
+		c.cache.Delete(strings.ToLower(p.Username))
+		c.publish(Event{Type: EventNameChanged, Query: p.UUID, UUID: np.UUID, Name: np.Username, OldName: p.Username})
+	}
+	c.cachePlayer(np)
+	c.publish(Event{Type: EventRefreshed, Query: p.UUID, UUID: np.UUID, Name: np.Username})
+}
@@ -0,0 +1,74 @@
+package mcaccutils
+
+import "time"
+
+// A Rename is a change of a player's name.
+type Rename struct {
+	// From and To are the names before and after the change.
+	From string
+	To   string
+	// ChangedAt is when the change was made.
+	ChangedAt time.Time
+}
+
+// renames returns the renames in history, a name history ordered oldest
+// first, whose entries satisfy keep.
+func renames(history []NameChange, keep func(i int) bool) []Rename {
+	var rs []Rename
+	// The first entry is the name the account was created with, so it is not
+	// a rename.
+	for i := 1; i < len(history); i++ {
+		if keep(i) {
+			rs = append(rs, Rename{From: history[i-1].Name, To: history[i].Name, ChangedAt: history[i].ChangedAt})
+		}
+	}
+	return rs
+}
+
+// RenamesSince returns the renames in history, as returned by GetNameHistory,
+// which were made after since, oldest first.
+func RenamesSince(history []NameChange, since time.Time) []Rename {
+	return renames(history, func(i int) bool {
+		return history[i].ChangedAt.After(since)
+	})
+}
+
+// DiffNameHistory compares two snapshots of the name history of a player, as
+// returned by GetNameHistory, and returns the renames in cur which are not in
+// old, oldest first. It is useful for spotting renames between two lookups
+// saved by the caller.
+func DiffNameHistory(old, cur []NameChange) []Rename {
+	// Compare times by instant, as snapshots restored from storage may be in
+	// a different location.
+	type change struct {
+		name string
+		at   int64
+	}
+	seen := make(map[change]bool, len(old))
+	for _, h := range old {
+		seen[change{h.Name, h.ChangedAt.UnixMilli()}] = true
+	}
+	return renames(cur, func(i int) bool {
+		return !seen[change{cur[i].Name, cur[i].ChangedAt.UnixMilli()}]
+	})
+}
+
+// GetRenamesSince looks up the name history of the player with the specified
+// UUID and returns the renames they have made after since, oldest first. It
+// can be used to show the names a player was formerly known as to people who
+// last saw them at since.
+//
+// As with GetNameHistory, the result is only kept in the short lived response
+// cache.
+func (c *Client) GetRenamesSince(uuid string, since time.Time) (renames []Rename, err error) {
+	history, err := c.GetNameHistory(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return RenamesSince(history, since), nil
+}
+
+// GetRenamesSince calls GetRenamesSince on the default client.
+func GetRenamesSince(uuid string, since time.Time) (renames []Rename, err error) {
+	return defaultClient.GetRenamesSince(uuid, since)
+}
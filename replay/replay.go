@@ -0,0 +1,173 @@
+// Package replay provides an http.RoundTripper which records responses from
+// real servers to fixture files, and replays them later. Tests which talk to
+// the Mojang API through it run deterministically and without a network
+// connection.
+//
+// A typical test records fixtures once with a flag:
+//
+//	var record = flag.Bool("record", false, "record API fixtures")
+//
+//	func TestLookup(t *testing.T) {
+//		cfg := mcaccutils.DefaultConfig()
+//		cfg.HTTPClient = &http.Client{Transport: &replay.Transport{
+//			Dir:    "testdata/lookup",
+//			Record: *record,
+//		}}
+//		c := mcaccutils.NewClient(cfg)
+//		...
+//	}
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// DefaultScrubHeaders are the headers removed from recorded fixtures when
+// Transport.ScrubHeaders is nil.
+var DefaultScrubHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Transport is an http.RoundTripper which records or replays fixtures.
+//
+// Each fixture is a JSON file in Dir, named after a hash of the method, URL
+// and body of the request, so identical requests share a fixture.
+type Transport struct {
+	// Dir is the directory fixtures are kept in.
+	Dir string
+
+	// Record makes the transport send requests to the real server through
+	// Base and save the responses, replacing existing fixtures. Otherwise
+	// every request is answered from its fixture, and requests without one
+	// fail.
+	Record bool
+
+	// Base is used to send requests when recording. If it is nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// ScrubHeaders lists the request and response headers which are left out
+	// of fixtures, such as those holding credentials. If it is nil,
+	// DefaultScrubHeaders is used.
+	ScrubHeaders []string
+}
+
+// fixture is the format of fixture files.
+type fixture struct {
+	Request struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header,omitempty"`
+		Body   []byte      `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header,omitempty"`
+		Body       []byte      `json:"body,omitempty"`
+	} `json:"response"`
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	path := t.path(req, body)
+	if t.Record {
+		return t.record(req, body, path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("replay: no fixture for %s %s", req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("replay: decoding %s: %w", path, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Response.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// record sends req to the real server and saves the response to path.
+func (t *Transport) record(req *http.Request, body []byte, path string) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	var f fixture
+	f.Request.Method = req.Method
+	f.Request.URL = req.URL.String()
+	f.Request.Header = t.scrub(req.Header)
+	f.Request.Body = body
+	f.Response.StatusCode = resp.StatusCode
+	f.Response.Header = t.scrub(resp.Header)
+	f.Response.Body = respBody
+	data, err := json.MarshalIndent(&f, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.Dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// path returns the fixture file for a request.
+func (t *Transport) path(req *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL)
+	h.Write(body)
+	return filepath.Join(t.Dir, hex.EncodeToString(h.Sum(nil))[:16]+".json")
+}
+
+// scrub returns a copy of header without the scrubbed headers.
+func (t *Transport) scrub(header http.Header) http.Header {
+	scrub := t.ScrubHeaders
+	if scrub == nil {
+		scrub = DefaultScrubHeaders
+	}
+	header = header.Clone()
+	for _, k := range scrub {
+		header.Del(k)
+	}
+	return header
+}
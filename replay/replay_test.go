@@ -0,0 +1,124 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// profileURL is the URL of the request recorded in testdata.
+const profileURL = "https://api.minecraftservices.com/minecraft/profile"
+
+// roundTripFunc is an http.RoundTripper standing in for a real server.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestReplay(t *testing.T) {
+	c := &http.Client{Transport: &Transport{Dir: "testdata"}}
+	resp, err := c.Get(profileURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	if want := `{"id":"069a79f444e94726a5befca90e38aaf5","name":"Notch"}`; string(body) != want {
+		t.Errorf("got body %s, want %s", body, want)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+}
+
+func TestMissingFixture(t *testing.T) {
+	c := &http.Client{Transport: &Transport{Dir: "testdata"}}
+	_, err := c.Get(profileURL + "/skins")
+	if err == nil || !strings.Contains(err.Error(), "replay: no fixture for GET "+profileURL+"/skins") {
+		t.Fatalf("got error %v, want a missing fixture error", err)
+	}
+}
+
+func TestRecordScrubsHeaders(t *testing.T) {
+	dir := t.TempDir()
+	server := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		h := http.Header{}
+		h.Set("Content-Type", "application/json")
+		h.Set("Set-Cookie", "session=s3cret")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     h,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"name":"Notch"}`)),
+			Request:    req,
+		}, nil
+	})
+	rec := &Transport{Dir: dir, Record: true, Base: server}
+	req, err := http.NewRequest(http.MethodGet, profileURL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Cookie", "sid=s3cret")
+	req.Header.Set("Accept", "application/json")
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("recorded %d fixtures (%v), want 1", len(files), err)
+	}
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("s3cret")) {
+		t.Errorf("fixture holds a scrubbed header:\n%s", data)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.Request.Header.Get("Accept"); got != "application/json" {
+		t.Errorf("recorded Accept header %q, want application/json", got)
+	}
+
+	// The recording replays without the server.
+	c := &http.Client{Transport: &Transport{Dir: dir}}
+	resp, err = c.Get(profileURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != `{"name":"Notch"}` {
+		t.Errorf("replayed body %s, want the recorded one", body)
+	}
+}
+
+func TestCustomScrubHeaders(t *testing.T) {
+	tr := &Transport{ScrubHeaders: []string{"X-Api-Key"}}
+	h := http.Header{}
+	h.Set("X-API-Key", "s3cret")
+	h.Set("Authorization", "Bearer kept")
+	got := tr.scrub(h)
+	if got.Get("X-API-Key") != "" || got.Get("Authorization") == "" {
+		t.Errorf("scrub kept %v, want only Authorization", got)
+	}
+	if h.Get("X-API-Key") == "" {
+		t.Error("scrub changed the original header")
+	}
+}
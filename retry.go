@@ -0,0 +1,175 @@
+package mcaccutils
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// A JitterStrategy selects how the delays between retries are randomised, so
+// many clients failing at once do not retry in lockstep.
+type JitterStrategy int
+
+const (
+	// JitterNone waits the full delay.
+	JitterNone JitterStrategy = iota
+	// JitterFull waits a random time between zero and the delay.
+	JitterFull
+	// JitterEqual waits half the delay plus a random time up to the other
+	// half.
+	JitterEqual
+)
+
+// defaultRetryableStatuses are the response statuses retried when a
+// RetryPolicy lists none.
+var defaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// A RetryPolicy describes how a Client retries requests which failed with a
+// network error or a retryable status. Every attempt waits for the rate
+// limiter. The zero RetryPolicy makes a single attempt.
+//
+// Interactive programs usually want few attempts and short delays, so users
+// are not kept waiting, while batch jobs can afford to be patient.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a request is sent, including the
+	// first. Zero or one means requests are not retried.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, which doubles for each
+	// one after it. Zero means 500 milliseconds.
+	BaseDelay time.Duration
+	// MaxDelay bounds the delay before a retry, including delays asked for
+	// with a Retry-After header. Zero means 30 seconds.
+	MaxDelay time.Duration
+	// Jitter randomises the delays.
+	Jitter JitterStrategy
+	// RetryableStatuses lists the response statuses which are retried. Nil
+	// means 429 Too Many Requests and the 500, 502, 503 and 504 server
+	// errors.
+	RetryableStatuses []int
+}
+
+// baseDelay returns the delay before the first retry.
+func (p RetryPolicy) baseDelay() time.Duration {
+	if p.BaseDelay > 0 {
+		return p.BaseDelay
+	}
+	return 500 * time.Millisecond
+}
+
+// maxDelay returns the longest delay before a retry.
+func (p RetryPolicy) maxDelay() time.Duration {
+	if p.MaxDelay > 0 {
+		return p.MaxDelay
+	}
+	return 30 * time.Second
+}
+
+// retryable reports whether the attempt which got resp and err should be
+// retried.
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = defaultRetryableStatuses
+	}
+	for _, s := range statuses {
+		if resp.StatusCode == s {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the time to wait before retrying after the given attempt,
+// counting from one, which got resp. A Retry-After header asking for longer
+// is honoured, up to the maximum delay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	max := p.maxDelay()
+	d := p.baseDelay()
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	switch p.Jitter {
+	case JitterFull:
+		d = time.Duration(rand.Int63n(int64(d) + 1))
+	case JitterEqual:
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+	if resp != nil {
+		if after := retryAfter(resp.Header); after > d {
+			d = after
+		}
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// retryKey is the context key under which the policy set by WithRetryPolicy
+// is stored.
+type retryKey struct{}
+
+// WithRetryPolicy returns a copy of ctx carrying p, which replaces
+// Config.Retry for the requests made by lookups given the context, such as
+// GetUUIDContext.
+func WithRetryPolicy(ctx context.Context, p RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryKey{}, p)
+}
+
+// retryPolicy returns the retry policy of requests with context ctx.
+func (c *Client) retryPolicy(ctx context.Context) RetryPolicy {
+	if p, ok := ctx.Value(retryKey{}).(RetryPolicy); ok {
+		return p
+	}
+	return c.Config().Retry
+}
+
+// retry sends req with send, retrying it as described by the retry policy of
+// its context.
+func (c *Client) retry(req *http.Request, send func(req *http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	p := c.retryPolicy(ctx)
+	for attempt := 1; ; attempt++ {
+		resp, err := send(req)
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(resp, err) {
+			return resp, err
+		}
+		// Requests with a body can only be sent again if it can be
+		// rewound.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+		wait := p.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.metrics.add("retries", 1)
+		select {
+		case <-orSystem(c.Config().Clock).After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		req = req.Clone(ctx)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package mcaccutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A Match is a player found by a search.
+type Match struct {
+	UUID string
+	Name string
+}
+
+// Ranks of the ways a name can match a search, best first.
+const (
+	matchExact = iota
+	matchPrefix
+	matchSubstring
+	matchFuzzy
+)
+
+// SearchCached returns up to limit players in the memory cache whose names
+// match query, without making any requests. Exact matches come first, then
+// names starting with query, then names containing it, and finally names
+// containing its letters in order, such as "ntch" for "Notch". Within each
+// group shorter names come first. A limit of zero or less returns every
+// match.
+//
+// It is intended for autocompletion in admin interfaces, so only players who
+// have been looked up recently can be found.
+func (c *Client) SearchCached(query string, limit int) []Match {
+	query = strings.ToLower(query)
+	type ranked struct {
+		Match
+		rank int
+	}
+	var found []ranked
+	for k, item := range c.cache.Items() {
+		p := item.Value
+		// Every player is stored twice, so only look at the name entries.
+		if p.notFound || k == p.UUID {
+			continue
+		}
+		if rank, ok := matchName(k, query); ok {
+			found = append(found, ranked{Match{UUID: p.UUID, Name: p.Username}, rank})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		a, b := found[i], found[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	matches := make([]Match, len(found))
+	for i, r := range found {
+		matches[i] = r.Match
+	}
+	return matches
+}
+
+// SearchCached calls SearchCached on the default client.
+func SearchCached(query string, limit int) []Match {
+	return defaultClient.SearchCached(query, limit)
+}
+
+// matchName reports whether the lowercased name matches the lowercased query,
+// and how well.
+func matchName(name, query string) (rank int, ok bool) {
+	switch {
+	case name == query:
+		return matchExact, true
+	case strings.HasPrefix(name, query):
+		return matchPrefix, true
+	case strings.Contains(name, query):
+		return matchSubstring, true
+	}
+	// Look for the letters of query in order.
+	i := 0
+	for j := 0; j < len(name) && i < len(query); j++ {
+		if name[j] == query[i] {
+			i++
+		}
+	}
+	return matchFuzzy, i == len(query)
+}
+
+// SearchNames returns up to limit players recorded in the history with a name
+// starting with prefix, ignoring case, ordered by name. A player is returned
+// once for each name they have been seen with. A limit of zero or less
+// returns every match.
+func (h *SQLHistory) SearchNames(prefix string, limit int) ([]Match, error) {
+	// Underscores are common in names, so escape them along with the other
+	// LIKE wildcards.
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix)) + "%"
+	query := "SELECT DISTINCT uuid, name, name_key FROM mcaccutils_history " +
+		"WHERE NOT not_found AND name_key LIKE " + h.dialect.Placeholder(1) + ` ESCAPE '\' ` +
+		"ORDER BY name_key, uuid"
+	args := []interface{}{pattern}
+	if limit > 0 {
+		query += " LIMIT " + h.dialect.Placeholder(2)
+		args = append(args, limit)
+	}
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: searching history for %q: %w", prefix, err)
+	}
+	defer rows.Close()
+	var matches []Match
+	for rows.Next() {
+		var (
+			m   Match
+			key string
+		)
+		if err := rows.Scan(&m.UUID, &m.Name, &key); err != nil {
+			return nil, fmt.Errorf("mcaccutils: searching history for %q: %w", prefix, err)
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("mcaccutils: searching history for %q: %w", prefix, err)
+	}
+	return matches, nil
+}
@@ -0,0 +1,218 @@
+package serverlist
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The NBT tag types.
+const (
+	tagEnd = iota
+	tagByte
+	tagShort
+	tagInt
+	tagLong
+	tagFloat
+	tagDouble
+	tagByteArray
+	tagString
+	tagList
+	tagCompound
+	tagIntArray
+	tagLongArray
+)
+
+// maxNBTDepth is the deepest nesting of lists and compounds accepted, so a
+// corrupt file cannot exhaust the stack.
+const maxNBTDepth = 512
+
+// nbtReader decodes uncompressed big endian NBT, as used by servers.dat.
+type nbtReader struct {
+	r   io.Reader
+	buf [8]byte
+}
+
+// readRoot reads the root tag, which must be a compound, returning its
+// contents.
+func (n *nbtReader) readRoot() (map[string]interface{}, error) {
+	typ, err := n.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if typ != tagCompound {
+		return nil, fmt.Errorf("serverlist: root tag has type %d, not compound", typ)
+	}
+	if _, err := n.readString(); err != nil {
+		return nil, err
+	}
+	v, err := n.readPayload(tagCompound, 0)
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+func (n *nbtReader) read(size int) ([]byte, error) {
+	b := n.buf[:size]
+	if _, err := io.ReadFull(n.r, b); err != nil {
+		return nil, fmt.Errorf("serverlist: reading NBT: %w", err)
+	}
+	return b, nil
+}
+
+func (n *nbtReader) readByte() (byte, error) {
+	b, err := n.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (n *nbtReader) readInt() (int32, error) {
+	b, err := n.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (n *nbtReader) readLong() (int64, error) {
+	b, err := n.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+func (n *nbtReader) readString() (string, error) {
+	b, err := n.read(2)
+	if err != nil {
+		return "", err
+	}
+	s := make([]byte, binary.BigEndian.Uint16(b))
+	if _, err := io.ReadFull(n.r, s); err != nil {
+		return "", fmt.Errorf("serverlist: reading NBT: %w", err)
+	}
+	// NBT uses modified UTF-8, which only differs from UTF-8 for NUL and
+	// characters outside the BMP, neither of which matter here.
+	return string(s), nil
+}
+
+// readLength reads the length of an array or list.
+func (n *nbtReader) readLength() (int, error) {
+	l, err := n.readInt()
+	if err != nil {
+		return 0, err
+	}
+	if l < 0 {
+		return 0, fmt.Errorf("serverlist: negative NBT length %d", l)
+	}
+	return int(l), nil
+}
+
+// readPayload reads the payload of a tag of type typ, nested depth levels
+// deep. Bytes, shorts, ints and longs are returned as int8, int16, int32 and
+// int64, compounds as a map[string]interface{}, and lists as a
+// []interface{}.
+func (n *nbtReader) readPayload(typ byte, depth int) (interface{}, error) {
+	if depth > maxNBTDepth {
+		return nil, fmt.Errorf("serverlist: NBT nested deeper than %d", maxNBTDepth)
+	}
+	switch typ {
+	case tagByte:
+		b, err := n.readByte()
+		return int8(b), err
+	case tagShort:
+		b, err := n.read(2)
+		if err != nil {
+			return nil, err
+		}
+		return int16(binary.BigEndian.Uint16(b)), nil
+	case tagInt:
+		return n.readInt()
+	case tagLong:
+		return n.readLong()
+	case tagFloat:
+		i, err := n.readInt()
+		return math.Float32frombits(uint32(i)), err
+	case tagDouble:
+		l, err := n.readLong()
+		return math.Float64frombits(uint64(l)), err
+	case tagByteArray:
+		l, err := n.readLength()
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, l)
+		if _, err := io.ReadFull(n.r, b); err != nil {
+			return nil, fmt.Errorf("serverlist: reading NBT: %w", err)
+		}
+		return b, nil
+	case tagString:
+		return n.readString()
+	case tagList:
+		elem, err := n.readByte()
+		if err != nil {
+			return nil, err
+		}
+		l, err := n.readLength()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, 0)
+		for i := 0; i < l; i++ {
+			v, err := n.readPayload(elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case tagCompound:
+		m := make(map[string]interface{})
+		for {
+			t, err := n.readByte()
+			if err != nil {
+				return nil, err
+			}
+			if t == tagEnd {
+				return m, nil
+			}
+			name, err := n.readString()
+			if err != nil {
+				return nil, err
+			}
+			if m[name], err = n.readPayload(t, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	case tagIntArray, tagLongArray:
+		l, err := n.readLength()
+		if err != nil {
+			return nil, err
+		}
+		if typ == tagIntArray {
+			a := make([]int32, 0)
+			for i := 0; i < l; i++ {
+				v, err := n.readInt()
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			}
+			return a, nil
+		}
+		a := make([]int64, 0)
+		for i := 0; i < l; i++ {
+			v, err := n.readLong()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("serverlist: unknown NBT tag type %d", typ)
+}
@@ -0,0 +1,263 @@
+package serverlist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// DefaultPort is the port Minecraft servers listen on unless told otherwise.
+const DefaultPort = 25565
+
+// maxPacketLength is the longest status response accepted, which is plenty
+// for a description, a favicon and a player sample.
+const maxPacketLength = 1 << 21
+
+// A Status is the status a server reports to the server list.
+type Status struct {
+	Version struct {
+		// Name is the version the server runs, such as "1.21.1" or
+		// "Paper 1.21.1".
+		Name string `json:"name"`
+		// Protocol is the protocol version the server speaks.
+		Protocol int `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+		// Sample lists some of the players online. Servers often fill
+		// it with decorative text instead.
+		Sample []SamplePlayer `json:"sample"`
+	} `json:"players"`
+	// Description is the message of the day, which is either a JSON string
+	// or a chat component.
+	Description json.RawMessage `json:"description"`
+	// Favicon is the icon of the server as a data URL, if it has one.
+	Favicon string `json:"favicon"`
+	// Latency is the round trip time of a ping sent after the status.
+	Latency time.Duration `json:"-"`
+}
+
+// A SamplePlayer is a player in the sample of a Status.
+type SamplePlayer struct {
+	Name string `json:"name"`
+	// UUID is the dashed UUID of the player, as reported by the server.
+	UUID string `json:"id"`
+}
+
+// resolve returns the address to dial for a server address, and the host and
+// port to announce in the handshake. If the address has no port, the
+// _minecraft._tcp SRV record of the host is followed, as the client does.
+func resolve(ctx context.Context, address string) (dial, host string, port int, err error) {
+	host, p, err := net.SplitHostPort(address)
+	if err == nil {
+		port, err = strconv.Atoi(p)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("serverlist: invalid port in %q", address)
+		}
+		return address, host, port, nil
+	}
+	host, port = address, DefaultPort
+	if _, srvs, err := net.DefaultResolver.LookupSRV(ctx, "minecraft", "tcp", host); err == nil && len(srvs) > 0 {
+		target := strings.TrimSuffix(srvs[0].Target, ".")
+		return net.JoinHostPort(target, strconv.Itoa(int(srvs[0].Port))), host, int(srvs[0].Port), nil
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), host, port, nil
+}
+
+// Ping connects to the server at address, which may omit the port, and
+// returns its status. ctx bounds the whole exchange.
+func Ping(ctx context.Context, address string) (*Status, error) {
+	dial, host, port, err := resolve(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", dial)
+	if err != nil {
+		return nil, fmt.Errorf("serverlist: pinging %s: %w", address, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Close the connection if ctx is canceled, which unblocks any read.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	s, err := ping(conn, host, port)
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, fmt.Errorf("serverlist: pinging %s: %w", address, err)
+	}
+	return s, nil
+}
+
+// ping runs the status exchange over conn.
+func ping(conn io.ReadWriter, host string, port int) (*Status, error) {
+	// Handshake, asking for the status state, followed by a status request.
+	var hs bytes.Buffer
+	putVarInt(&hs, 0x00)
+	putVarInt(&hs, -1)
+	putString(&hs, host)
+	binary.Write(&hs, binary.BigEndian, uint16(port))
+	putVarInt(&hs, 1)
+	if err := writePacket(conn, hs.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writePacket(conn, []byte{0x00}); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil {
+		return nil, err
+	}
+	pr := bytes.NewReader(p)
+	if id, err := readVarInt(pr); err != nil || id != 0x00 {
+		return nil, errors.New("unexpected status response")
+	}
+	n, err := readVarInt(pr)
+	if err != nil || n < 0 || int(n) > pr.Len() {
+		return nil, errors.New("malformed status response")
+	}
+	js := make([]byte, n)
+	pr.Read(js)
+	s := &Status{}
+	if err := json.Unmarshal(js, s); err != nil {
+		return nil, fmt.Errorf("decoding status: %w", err)
+	}
+	// Measure the latency with a ping, which some servers do not answer.
+	var pb bytes.Buffer
+	putVarInt(&pb, 0x01)
+	start := time.Now()
+	binary.Write(&pb, binary.BigEndian, start.UnixNano())
+	if err := writePacket(conn, pb.Bytes()); err == nil {
+		if _, err := readPacket(r); err == nil {
+			s.Latency = time.Since(start)
+		}
+	}
+	return s, nil
+}
+
+func putVarInt(w *bytes.Buffer, v int32) {
+	u := uint32(v)
+	for u >= 0x80 {
+		w.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	w.WriteByte(byte(u))
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(v), nil
+		}
+	}
+	return 0, errors.New("VarInt too long")
+}
+
+func putString(w *bytes.Buffer, s string) {
+	putVarInt(w, int32(len(s)))
+	w.WriteString(s)
+}
+
+// writePacket writes an uncompressed packet with payload p, which starts with
+// the packet id.
+func writePacket(w io.Writer, p []byte) error {
+	var b bytes.Buffer
+	putVarInt(&b, int32(len(p)))
+	b.Write(p)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// readPacket reads an uncompressed packet, returning its payload, which
+// starts with the packet id.
+func readPacket(r *bufio.Reader) ([]byte, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxPacketLength {
+		return nil, fmt.Errorf("packet length %d out of range", n)
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// A Result is the outcome of pinging one server with PingAll.
+type Result struct {
+	Server Server
+	// Status is the status of the server, or nil if the ping failed.
+	Status *Status
+	// Players holds the result of looking up each player in the sample of
+	// the status by name, in order.
+	Players []mcaccutils.UUIDResult
+	// Err is the error which prevented the server being pinged, if any.
+	Err error
+}
+
+// PingAll pings every server concurrently, then looks up the names of the
+// sampled players with c in as few batches as possible, which shows the real
+// UUID of each, whatever the server reported. It returns a result for each
+// server, in order.
+func PingAll(ctx context.Context, c *mcaccutils.Client, servers []Server) []Result {
+	results := make([]Result, len(servers))
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		results[i].Server = s
+		wg.Add(1)
+		go func(r *Result) {
+			defer wg.Done()
+			r.Status, r.Err = Ping(ctx, r.Server.Address)
+		}(&results[i])
+	}
+	wg.Wait()
+	var names []string
+	for _, r := range results {
+		if r.Status != nil {
+			for _, p := range r.Status.Players.Sample {
+				names = append(names, p.Name)
+			}
+		}
+	}
+	lookups := c.GetUUIDsContext(ctx, names)
+	for i := range results {
+		if s := results[i].Status; s != nil {
+			n := len(s.Players.Sample)
+			results[i].Players, lookups = lookups[:n:n], lookups[n:]
+		}
+	}
+	return results
+}
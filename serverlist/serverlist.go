@@ -0,0 +1,68 @@
+// Package serverlist reads the multiplayer server list saved by the
+// Minecraft client in servers.dat, and queries servers for their status the
+// way the client does, resolving the players they report with a
+// mcaccutils.Client.
+package serverlist
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// A Server is an entry of the multiplayer server list.
+type Server struct {
+	// Name is the name the player gave the server.
+	Name string
+	// Address is the address of the server, with an optional port.
+	Address string
+	// Icon is the base64 encoded PNG icon last received from the server,
+	// if any.
+	Icon string
+	// AcceptTextures records whether the player chose to accept resource
+	// packs from the server. It is nil if they were never asked.
+	AcceptTextures *bool
+	// Hidden marks servers added by the game, such as those added by
+	// Realms invitations, which are not shown in the list.
+	Hidden bool
+}
+
+// Read reads a server list in the uncompressed NBT format of servers.dat.
+func Read(r io.Reader) ([]Server, error) {
+	root, err := (&nbtReader{r: bufio.NewReader(r)}).readRoot()
+	if err != nil {
+		return nil, err
+	}
+	list, _ := root["servers"].([]interface{})
+	servers := make([]Server, 0, len(list))
+	for _, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var s Server
+		s.Name, _ = m["name"].(string)
+		s.Address, _ = m["ip"].(string)
+		s.Icon, _ = m["icon"].(string)
+		if b, ok := m["acceptTextures"].(int8); ok {
+			accept := b != 0
+			s.AcceptTextures = &accept
+		}
+		if b, ok := m["hidden"].(int8); ok {
+			s.Hidden = b != 0
+		}
+		servers = append(servers, s)
+	}
+	return servers, nil
+}
+
+// ReadFile reads the server list saved in the file at path, usually
+// servers.dat in the Minecraft directory.
+func ReadFile(path string) ([]Server, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
@@ -0,0 +1,208 @@
+// Package serverlog extracts player activity from the logs of vanilla,
+// Spigot, Paper and Forge servers, for audit tools which need to know who was
+// online when, without installing a server plugin.
+package serverlog
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// An EventType is the kind of an Event.
+type EventType int
+
+const (
+	// Join is a player joining the server.
+	Join EventType = iota
+	// Leave is a player leaving the server, including when it stops.
+	Leave
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Join:
+		return "join"
+	case Leave:
+		return "leave"
+	}
+	return "EventType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// An Event is a player joining or leaving the server.
+type Event struct {
+	Type EventType
+	Time time.Time
+	Name string
+	// UUID is the undashed UUID the server gave the player, or empty if the
+	// log did not say. Servers in offline mode give players version 3
+	// UUIDs derived from their name.
+	UUID string
+	// Address is the address the player connected from, if logged.
+	Address string
+	// Reason is why the player left, if logged.
+	Reason string
+}
+
+// A Session is the time a player spent on the server.
+type Session struct {
+	Name    string
+	UUID    string
+	Address string
+	Joined  time.Time
+	// Left is when the player left, or the zero time if they were still
+	// online at the end of the log.
+	Left   time.Time
+	Reason string
+}
+
+var (
+	linePattern  = regexp.MustCompile(`^\[(\d\d):(\d\d):(\d\d)(?:\.\d+)?(?: \w+)?\](?: \[[^\]]*\])*: (.*)$`)
+	uuidPattern  = regexp.MustCompile(`^UUID of player (\w+) is ([0-9a-fA-F-]{32,36})$`)
+	loginPattern = regexp.MustCompile(`^(\w+)\[/?([^\]]*)\] logged in with entity id`)
+	joinPattern  = regexp.MustCompile(`^(\w+) joined the game`)
+	lostPattern  = regexp.MustCompile(`^(\w+) lost connection: (.*)$`)
+	leavePattern = regexp.MustCompile(`^(\w+) left the game`)
+	stopPattern  = regexp.MustCompile(`^Stopping (?:the )?server`)
+)
+
+// A Parser turns log lines into events, tracking the players online. The
+// zero value is not usable; use NewParser.
+type Parser struct {
+	day  time.Time
+	last time.Duration
+	// uuids and addresses hold what was logged about players before they
+	// joined, and reasons why they lost connection before they left.
+	uuids     map[string]string
+	addresses map[string]string
+	reasons   map[string]string
+	online    map[string]Event
+}
+
+// NewParser returns a Parser for a log started on the day of date, in its
+// location. Log lines only carry the time of day, so the parser moves to the
+// next day whenever the time goes backwards.
+func NewParser(date time.Time) *Parser {
+	y, m, d := date.Date()
+	return &Parser{
+		day:       time.Date(y, m, d, 0, 0, 0, 0, date.Location()),
+		uuids:     make(map[string]string),
+		addresses: make(map[string]string),
+		reasons:   make(map[string]string),
+		online:    make(map[string]Event),
+	}
+}
+
+// ParseLine parses one line of the log, returning the events it records,
+// which number more than one only when the server stops with players online.
+func (p *Parser) ParseLine(line string) []Event {
+	m := linePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if m == nil {
+		return nil
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	s, _ := strconv.Atoi(m[3])
+	tod := time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(s)*time.Second
+	if tod < p.last {
+		p.day = p.day.AddDate(0, 0, 1)
+	}
+	p.last = tod
+	t := p.day.Add(tod)
+	msg := m[4]
+	if m := uuidPattern.FindStringSubmatch(msg); m != nil {
+		p.uuids[m[1]] = strings.ToLower(strings.Replace(m[2], "-", "", -1))
+		return nil
+	}
+	if m := loginPattern.FindStringSubmatch(msg); m != nil {
+		p.addresses[m[1]] = m[2]
+		return nil
+	}
+	if m := joinPattern.FindStringSubmatch(msg); m != nil {
+		name := m[1]
+		e := Event{Type: Join, Time: t, Name: name, UUID: p.uuids[name], Address: p.addresses[name]}
+		delete(p.addresses, name)
+		p.online[name] = e
+		return []Event{e}
+	}
+	if m := lostPattern.FindStringSubmatch(msg); m != nil {
+		p.reasons[m[1]] = m[2]
+		return nil
+	}
+	if m := leavePattern.FindStringSubmatch(msg); m != nil {
+		return []Event{p.leave(m[1], t, p.reasons[m[1]])}
+	}
+	if stopPattern.MatchString(msg) {
+		var events []Event
+		for name := range p.online {
+			events = append(events, p.leave(name, t, "Server closed"))
+		}
+		return events
+	}
+	return nil
+}
+
+// leave records that the named player left at t.
+func (p *Parser) leave(name string, t time.Time, reason string) Event {
+	joined, ok := p.online[name]
+	e := Event{Type: Leave, Time: t, Name: name, UUID: p.uuids[name], Reason: reason}
+	if ok {
+		e.UUID, e.Address = joined.UUID, joined.Address
+	}
+	delete(p.online, name)
+	delete(p.reasons, name)
+	return e
+}
+
+// UUIDs returns the UUID logged for each player seen so far, keyed by name.
+func (p *Parser) UUIDs() map[string]string {
+	uuids := make(map[string]string, len(p.uuids))
+	for k, v := range p.uuids {
+		uuids[k] = v
+	}
+	return uuids
+}
+
+// Parse reads a whole log, started on the day of date, and returns the
+// sessions it records, in the order the players joined.
+//
+// If c is not nil, the UUID of every player the server authenticated with
+// Mojang is saved in the cache of c, so looking them up later does not need a
+// request. UUIDs from servers in offline mode are skipped.
+func Parse(r io.Reader, date time.Time, c *mcaccutils.Client) ([]Session, error) {
+	p := NewParser(date)
+	var sessions []Session
+	open := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		for _, e := range p.ParseLine(sc.Text()) {
+			switch e.Type {
+			case Join:
+				open[e.Name] = len(sessions)
+				sessions = append(sessions, Session{Name: e.Name, UUID: e.UUID, Address: e.Address, Joined: e.Time})
+			case Leave:
+				if i, ok := open[e.Name]; ok {
+					sessions[i].Left, sessions[i].Reason = e.Time, e.Reason
+					delete(open, e.Name)
+				}
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return sessions, err
+	}
+	if c != nil {
+		for name, uuid := range p.UUIDs() {
+			if mcaccutils.IsOnlineUUID(uuid) {
+				c.Remember(uuid, name)
+			}
+		}
+	}
+	return sessions, nil
+}
@@ -0,0 +1,150 @@
+package serverlog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch follows the log at path, usually logs/latest.log in the server
+// directory, calling f with every player joining or leaving from then on,
+// until ctx is done, when it returns an error matching
+// mcaccutils.ErrCanceled. The lines already in the log are read first, so
+// players who joined before Watch was called are still tracked, but no events
+// are sent for them.
+//
+// If c is not nil, events without a UUID in the log are given the UUID of the
+// player looked up with c, and the UUIDs the server authenticated with Mojang
+// are saved in the cache of c. The log is reopened from the start when the
+// server replaces it on restart.
+func Watch(ctx context.Context, path string, c *mcaccutils.Client, f func(e Event)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("serverlog: watching %s: %w", path, err)
+	}
+	defer w.Close()
+	// Watch the directory rather than the file, so the log is noticed when
+	// it is recreated.
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("serverlog: watching %s: %w", path, err)
+	}
+	t := &tail{path: path, c: c, f: f}
+	defer t.close()
+	if err := t.open(false); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("serverlog: watching %s: %w", path, err)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", mcaccutils.ErrCanceled, ctx.Err())
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("serverlog: watching %s: %w", path, err)
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) != filepath.Clean(path) || !ev.Has(fsnotify.Create|fsnotify.Write) {
+				continue
+			}
+			if err := t.update(); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("serverlog: watching %s: %w", path, err)
+			}
+		}
+	}
+}
+
+// tail reads the lines appended to a log.
+type tail struct {
+	path string
+	c    *mcaccutils.Client
+	f    func(e Event)
+
+	file *os.File
+	r    *bufio.Reader
+	// partial holds the start of a line still being written.
+	partial string
+	p       *Parser
+}
+
+// open opens the log from the start, sending the events in it to f if emit
+// is set.
+func (t *tail) open(emit bool) error {
+	t.close()
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	t.file, t.r, t.partial = file, bufio.NewReader(file), ""
+	t.p = NewParser(time.Now())
+	return t.read(emit)
+}
+
+// update reads the lines added to the log since the last call, reopening it
+// if it was replaced or truncated.
+func (t *tail) update() error {
+	if t.file == nil {
+		return t.open(true)
+	}
+	cur, err := t.file.Stat()
+	if err != nil {
+		return err
+	}
+	next, err := os.Stat(t.path)
+	if err != nil {
+		return err
+	}
+	pos, err := t.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(cur, next) || next.Size() < pos-int64(t.r.Buffered()) {
+		return t.open(true)
+	}
+	return t.read(true)
+}
+
+// read parses the complete lines available, sending the events to f if emit
+// is set.
+func (t *tail) read(emit bool) error {
+	for {
+		line, err := t.r.ReadString('\n')
+		if err == io.EOF {
+			t.partial += line
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, t.partial = t.partial+line, ""
+		for _, e := range t.p.ParseLine(line) {
+			if mcaccutils.IsOnlineUUID(e.UUID) && t.c != nil {
+				t.c.Remember(e.UUID, e.Name)
+			}
+			if !emit {
+				continue
+			}
+			if e.UUID == "" && t.c != nil {
+				e.UUID, _, _ = t.c.GetUUID(e.Name)
+			}
+			t.f(e)
+		}
+	}
+}
+
+// close closes the log, if it is open.
+func (t *tail) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
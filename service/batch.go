@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// maxBatch is the largest number of queries accepted in one batch request.
+const maxBatch = 1000
+
+// uuidPattern matches UUIDs, with or without dashes.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// A BatchResult is the result of one query of a batch request, as encoded in
+// the response.
+type BatchResult struct {
+	// Query is the name or UUID which was looked up.
+	Query string `json:"query"`
+	// UUID and Name are the undashed UUID and current name of the player,
+	// if they were found.
+	UUID string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Error says why the player was not found, if they were not: one of
+	// "not_found", "invalid", "rate_limited", "retired" or "failed".
+	Error string `json:"error,omitempty"`
+	// Message describes the error.
+	Message string `json:"message,omitempty"`
+}
+
+// errorCode returns the code of err in a BatchResult.
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, mcaccutils.ErrInvalidName):
+		return "invalid"
+	case errors.Is(err, mcaccutils.ErrPlayerNotFound):
+		return "not_found"
+	case errors.Is(err, mcaccutils.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, mcaccutils.ErrEndpointRetired):
+		return "retired"
+	}
+	return "failed"
+}
+
+// setError records err in r.
+func (r *BatchResult) setError(err error) {
+	if err != nil {
+		r.Error, r.Message = errorCode(err), err.Error()
+	}
+}
+
+// serveBatch answers POST /batch, whose body is a JSON array of names and
+// UUIDs, with a JSON array holding a BatchResult for each, in order. Names are
+// looked up in batches, and UUIDs a few at a time.
+func (h *Handler) serveBatch(w http.ResponseWriter, r *http.Request, key *APIKey) {
+	var queries []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&queries); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(queries) > maxBatch {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "Too many queries")
+		return
+	}
+	if !h.allow(w, r, key, len(queries)) {
+		return
+	}
+	results := make([]BatchResult, len(queries))
+	var names []string
+	var nameIdx, uuidIdx []int
+	for i, q := range queries {
+		results[i].Query = q
+		if uuidPattern.MatchString(q) {
+			uuidIdx = append(uuidIdx, i)
+		} else {
+			names = append(names, q)
+			nameIdx = append(nameIdx, i)
+		}
+	}
+	for j, res := range h.Client.GetUUIDsContext(r.Context(), names) {
+		results[nameIdx[j]].UUID, results[nameIdx[j]].Name = res.UUID, res.Name
+		results[nameIdx[j]].setError(res.Err)
+	}
+	// There is no batch endpoint for UUIDs.
+	workers := h.Client.Config().BulkConcurrency
+	if workers <= 0 {
+		workers = 4
+	}
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				res := &results[i]
+				name, err := h.Client.GetNameContext(r.Context(), res.Query)
+				if err == nil {
+					res.UUID, res.Name = strings.ToLower(strings.Replace(res.Query, "-", "", -1)), name
+				}
+				res.setError(err)
+			}
+		}()
+	}
+	for _, i := range uuidIdx {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	writeJSON(w, http.StatusOK, results)
+}
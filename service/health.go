@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds the time taken by the readiness checks.
+const readyTimeout = 5 * time.Second
+
+// Readiness is the body of the responses to /readyz.
+type Readiness struct {
+	// Ready reports whether every check passed.
+	Ready bool `json:"ready"`
+	// Checks maps the name of each check to "ok" or the reason it failed.
+	// The store is checked as "store", and each upstream service by its
+	// URL.
+	Checks map[string]string `json:"checks"`
+}
+
+// serveHealth answers liveness checks. The handler is alive as long as it
+// answers at all.
+func (h *Handler) serveHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte("ok\n"))
+}
+
+// serveReady answers readiness checks, with 503 Service Unavailable if the
+// store of the client, or an upstream service when CheckUpstream is set,
+// cannot be reached.
+func (h *Handler) serveReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	res := Readiness{Ready: true, Checks: make(map[string]string)}
+	check := func(name string, err error) {
+		if err != nil {
+			res.Ready = false
+			res.Checks[name] = err.Error()
+			return
+		}
+		res.Checks[name] = "ok"
+	}
+	check("store", h.Client.PingStore(ctx))
+	if h.CheckUpstream {
+		for _, p := range h.Client.Ping(ctx) {
+			check(p.URL, p.Err)
+		}
+	}
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, res)
+}
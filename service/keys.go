@@ -0,0 +1,157 @@
+package service
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// A Scope is a set of permissions granted to an API key.
+type Scope string
+
+const (
+	// ScopeLookup allows looking players up.
+	ScopeLookup Scope = "lookup"
+	// ScopeAdmin allows managing the service, such as removing players
+	// from the cache, as well as looking players up.
+	ScopeAdmin Scope = "admin"
+)
+
+// An APIKey identifies a consumer of the service.
+type APIKey struct {
+	// Name identifies the consumer in logs.
+	Name string `json:"name"`
+	// Key is the secret the consumer sends, either as a bearer token in the
+	// Authorization header or in the X-API-Key header.
+	Key string `json:"key"`
+	// Scopes lists what the consumer may do.
+	Scopes []Scope `json:"scopes"`
+	// RateLimit, if not zero, replaces Handler.ConsumerRateLimit for the
+	// consumer. A negative value means no limit.
+	RateLimit int `json:"rateLimit,omitempty"`
+}
+
+// allows reports whether k grants the scope s.
+func (k *APIKey) allows(s Scope) bool {
+	for _, ks := range k.Scopes {
+		if ks == s || ks == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// validate reports an error if k has no secret or grants an unknown scope.
+func (k *APIKey) validate() error {
+	if k.Key == "" {
+		return fmt.Errorf("service: API key %q has no key", k.Name)
+	}
+	for _, s := range k.Scopes {
+		if s != ScopeLookup && s != ScopeAdmin {
+			return fmt.Errorf("service: API key %q has unknown scope %q", k.Name, s)
+		}
+	}
+	return nil
+}
+
+// LoadKeys reads API keys from the JSON file at path, which holds an array
+// of APIKey objects. Keys without a secret or with unknown scopes are
+// rejected.
+func LoadKeys(path string) ([]APIKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []APIKey
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("service: reading keys from %s: %w", path, err)
+	}
+	for i := range keys {
+		if err := keys[i].validate(); err != nil {
+			return nil, fmt.Errorf("%w in %s", err, path)
+		}
+	}
+	return keys, nil
+}
+
+// ParseKeys parses API keys in the compact form used in environment
+// variables: whitespace separated entries of the form name:key:scopes, where
+// scopes is a comma separated list.
+//
+//	MCACCUTILS_API_KEYS="website:s3cret:lookup ops:t0psecret:admin"
+func ParseKeys(s string) ([]APIKey, error) {
+	var keys []APIKey
+	for _, f := range strings.Fields(s) {
+		parts := strings.SplitN(f, ":", 3)
+		if len(parts) != 3 || parts[1] == "" {
+			return nil, fmt.Errorf("service: invalid API key entry %q", f)
+		}
+		k := APIKey{Name: parts[0], Key: parts[1]}
+		for _, s := range strings.Split(parts[2], ",") {
+			k.Scopes = append(k.Scopes, Scope(s))
+		}
+		if err := k.validate(); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// requestKey returns the API key sent with r, if any.
+func requestKey(r *http.Request) string {
+	if k := r.Header.Get("X-API-Key"); k != "" {
+		return k
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return ""
+}
+
+// authenticate returns the API key of the consumer sending r, or nil if the
+// handler has no keys. If r has no valid key, or the key does not grant
+// scope, it writes an error response and reports ok as false.
+func (h *Handler) authenticate(w http.ResponseWriter, r *http.Request, scope Scope) (key *APIKey, ok bool) {
+	if len(h.Keys) == 0 {
+		return nil, true
+	}
+	sent := []byte(requestKey(r))
+	for i := range h.Keys {
+		k := &h.Keys[i]
+		// An empty key would match requests sending none.
+		if k.Key == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare(sent, []byte(k.Key)) == 1 {
+			key = k
+		}
+	}
+	if key == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcaccutils"`)
+		writeError(w, r, http.StatusUnauthorized, "Missing or invalid API key")
+		return nil, false
+	}
+	if !key.allows(scope) {
+		writeError(w, r, http.StatusForbidden, fmt.Sprintf("API key lacks the %s scope", scope))
+		return nil, false
+	}
+	return key, true
+}
+
+// serveInvalidate answers POST /admin/invalidate, whose body is a JSON array
+// of names and UUIDs to remove from the cache.
+func (h *Handler) serveInvalidate(w http.ResponseWriter, r *http.Request) {
+	var queries []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&queries); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	for _, q := range queries {
+		h.Client.Invalidate(q)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+func TestKeylessRequestRejected(t *testing.T) {
+	h := &Handler{
+		Client: mcaccutils.NewClient(mcaccutils.DefaultConfig()),
+		Keys:   []APIKey{{Name: "x", Scopes: []Scope{ScopeAdmin}}},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin/invalidate", strings.NewReader(`["Notch"]`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("request without a key got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestLoadKeysValidates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tt := range []struct {
+		json string
+		ok   bool
+	}{
+		{`[{"name":"x","key":"s3cret","scopes":["lookup"]}]`, true},
+		{`[{"name":"x","scopes":["admin"]}]`, false},
+		{`[{"name":"x","key":"s3cret","scopes":["root"]}]`, false},
+	} {
+		path := filepath.Join(dir, "keys.json")
+		if err := ioutil.WriteFile(path, []byte(tt.json), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadKeys(path)
+		if (err == nil) != tt.ok {
+			t.Errorf("LoadKeys(%s) returned error %v", tt.json, err)
+		}
+	}
+}
+
+func TestParseKeysValidates(t *testing.T) {
+	if _, err := ParseKeys("website:s3cret:lookup ops:t0psecret:admin"); err != nil {
+		t.Errorf("ParseKeys rejected valid keys: %v", err)
+	}
+	if _, err := ParseKeys("website:s3cret:root"); err == nil {
+		t.Error("ParseKeys accepted an unknown scope")
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxIdleBuckets is the number of consumer buckets kept before full ones are
+// dropped, which bounds the memory used by consumers who went away.
+const maxIdleBuckets = 10000
+
+// bucket is the token bucket of one consumer.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// consumerID returns the name the rate limit of the consumer sending r is
+// kept under: the name of its API key, or its IP address.
+func consumerID(r *http.Request, key *APIKey) string {
+	if key != nil {
+		return "key:" + key.Name
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// consumerLimit returns the number of requests per period the consumer with
+// the API key key may make, or zero for no limit.
+func (h *Handler) consumerLimit(key *APIKey) (n int, per time.Duration) {
+	n, per = h.ConsumerRateLimit, h.ConsumerRateLimitPeriod
+	if key != nil && key.RateLimit != 0 {
+		n = key.RateLimit
+	}
+	if per <= 0 {
+		per = time.Minute
+	}
+	return n, per
+}
+
+// allow takes cost tokens, at least one, from the bucket of the consumer
+// sending r, reporting whether there were enough. If not, it takes none and
+// writes a 429 response saying when to retry, or a 413 response if the
+// bucket can never hold that many.
+func (h *Handler) allow(w http.ResponseWriter, r *http.Request, key *APIKey, cost int) bool {
+	n, per := h.consumerLimit(key)
+	if n <= 0 {
+		return true
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	if cost > n {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "Too many queries for the consumer rate limit")
+		return false
+	}
+	rate := float64(n) / per.Seconds()
+	id := consumerID(r, key)
+	now := time.Now()
+	h.bucketMu.Lock()
+	if h.buckets == nil {
+		h.buckets = make(map[string]*bucket)
+	}
+	b, ok := h.buckets[id]
+	if !ok {
+		if len(h.buckets) >= maxIdleBuckets {
+			h.dropFullBuckets(now, rate, float64(n))
+		}
+		b = &bucket{tokens: float64(n), last: now}
+		h.buckets[id] = b
+	}
+	b.tokens = math.Min(float64(n), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	allowed := b.tokens >= float64(cost)
+	if allowed {
+		b.tokens -= float64(cost)
+	}
+	wait := (float64(cost) - b.tokens) / rate
+	h.bucketMu.Unlock()
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait))))
+		writeError(w, r, http.StatusTooManyRequests, "Consumer rate limit exceeded")
+	}
+	return allowed
+}
+
+// dropFullBuckets removes the buckets which have refilled, as their
+// consumers have been idle. h.bucketMu must be held.
+func (h *Handler) dropFullBuckets(now time.Time, rate, size float64) {
+	for id, b := range h.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rate >= size {
+			delete(h.buckets, id)
+		}
+	}
+}
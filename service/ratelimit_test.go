@@ -0,0 +1,36 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+func TestBatchChargedPerQuery(t *testing.T) {
+	h := &Handler{
+		Client:            mcaccutils.NewClient(mcaccutils.DefaultConfig()),
+		ConsumerRateLimit: 5,
+	}
+	// Invalid names are rejected without an upstream request.
+	batch := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := batch(`["a!","b!","c!"]`); code != http.StatusOK {
+		t.Fatalf("first batch got status %d, want 200", code)
+	}
+	if code := batch(`["a!","b!","c!"]`); code != http.StatusTooManyRequests {
+		t.Errorf("batch over the remaining tokens got status %d, want 429", code)
+	}
+	if code := batch(`["a!","b!"]`); code != http.StatusOK {
+		t.Errorf("batch within the remaining tokens got status %d, want 200", code)
+	}
+	if code := batch(`["a!","b!","c!","d!","e!","f!"]`); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("batch over the bucket size got status %d, want 413", code)
+	}
+}
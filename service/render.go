@@ -0,0 +1,35 @@
+package service
+
+import (
+	"image"
+	"net/http"
+	"strings"
+
+	"github.com/bearbin/go-mcaccutils/skin"
+)
+
+// renderers maps the kinds of render served under /render/ to the functions
+// drawing them.
+var renderers = map[string]func(s image.Image, size int) *image.NRGBA{
+	"face": skin.Face,
+	"head": skin.Head3D,
+	"body": skin.FullBody,
+}
+
+// serveRender serves a render of the kind named by the first element of
+// path, with the rest of path handled by a skin.Handler.
+func (h *Handler) serveRender(w http.ResponseWriter, r *http.Request, path string) {
+	kind, rest := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		kind, rest = path[:i], path[i:]
+	}
+	render, ok := renderers[kind]
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "Not Found")
+		return
+	}
+	sh := &skin.Handler{Client: h.Client, Render: render, Cache: h.SkinCache, Formats: h.RenderFormats}
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = rest
+	sh.ServeHTTP(w, r2)
+}
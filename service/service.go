@@ -0,0 +1,303 @@
+// Package service serves player lookups over HTTP from a mcaccutils.Client,
+// so many programs on a network can share its cache and its rate limit
+// quota.
+//
+// Its Handler mirrors the paths of the Mojang APIs, so existing tools only
+// need their base URL changed to use it:
+//
+//	c := mcaccutils.NewClient(mcaccutils.DefaultConfig())
+//	http.ListenAndServe(":8080", &service.Handler{Client: c})
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	"github.com/bearbin/go-mcaccutils/skin"
+)
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// maxBulkNames is the largest number of names Mojang accepts in one bulk
+// lookup.
+const maxBulkNames = 10
+
+// Handler is an http.Handler answering the lookup requests of the Mojang
+// APIs from the cache of Client, and forwarding misses to the real APIs
+// within its rate limit. It serves:
+//
+//	GET  /users/profiles/minecraft/{name}
+//	POST /profiles/minecraft
+//	GET  /user/profiles/{uuid}/names
+//	GET  /session/minecraft/profile/{uuid}[?unsigned=false]
+//	GET  /minecraft/profile/lookup/name/{name}
+//	POST /minecraft/profile/lookup/bulk/byname
+//
+// The responses have the same format as those of Mojang. It also serves
+//
+//	POST /batch
+//
+// which looks up a JSON array of names and UUIDs in one request, answering
+// with a JSON array of BatchResult values,
+//
+//	POST /admin/invalidate
+//
+// which removes a JSON array of names and UUIDs from the cache, and
+//
+//	GET  /events
+//
+// which streams the name and skin changes found by Client as server-sent
+// events, named name_changed and skin_changed, whose data is a StreamEvent.
+//
+// If Renders is set, it also draws the skins of players, so a network can
+// serve its own avatars:
+//
+//	GET  /render/face/{query}
+//	GET  /render/head/{query}
+//	GET  /render/body/{query}
+//
+// where query is a name or UUID, optionally followed by /{size} and an
+// extension, as described by skin.Handler.
+//
+// Orchestrators can check on it through
+//
+//	GET  /healthz
+//	GET  /readyz
+//
+// which need no API key. /healthz answers as long as the handler runs, and
+// /readyz answers with a Readiness value, and 503 Service Unavailable unless
+// the store of Client, and the upstream APIs if CheckUpstream is set, can be
+// reached.
+type Handler struct {
+	// Client is used to look up players. It must not be nil.
+	Client *mcaccutils.Client
+
+	// Keys, if not empty, lists the API keys allowed to use the handler.
+	// Requests without one of them are rejected. Otherwise anyone may look
+	// players up, and the admin endpoints are disabled.
+	Keys []APIKey
+
+	// ConsumerRateLimit is the number of requests each consumer may make per
+	// ConsumerRateLimitPeriod, whatever the rate limit of Client allows, so
+	// one consumer cannot use up the quota of everyone else. Consumers are
+	// told apart by API key if the handler has keys, and by IP address
+	// otherwise. Requests to /batch and the bulk lookup endpoints count once
+	// for each name or UUID in them, as each may need an upstream lookup.
+	// Zero means no limit. APIKey.RateLimit overrides it.
+	ConsumerRateLimit int
+	// ConsumerRateLimitPeriod is the period of ConsumerRateLimit. Zero means
+	// one minute.
+	ConsumerRateLimitPeriod time.Duration
+
+	// CheckUpstream makes /readyz also check that the upstream APIs of
+	// Client can be reached. The checks do not count towards its rate
+	// limit.
+	CheckUpstream bool
+
+	// Renders enables the /render/ endpoints.
+	Renders bool
+	// SkinCache, if set, keeps the skins downloaded for renders on disk.
+	SkinCache *skin.DiskCache
+	// RenderFormats lists the formats renders may be requested in besides
+	// PNG, as skin.Handler.Formats does, such as WebP with the encoder of
+	// the skin/webp package.
+	RenderFormats map[string]skin.Encoder
+
+	// bucketMu guards buckets, which holds the token bucket of each
+	// consumer.
+	bucketMu sync.Mutex
+	buckets  map[string]*bucket
+}
+
+// mojangProfile is a player in the responses of the lookup endpoints.
+type mojangProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == "/healthz":
+		h.serveHealth(w, r)
+		return
+	case r.Method == http.MethodGet && path == "/readyz":
+		h.serveReady(w, r)
+		return
+	}
+	if strings.HasPrefix(path, "/admin/") {
+		if len(h.Keys) == 0 {
+			writeError(w, r, http.StatusNotFound, "Not Found")
+			return
+		}
+		if _, ok := h.authenticate(w, r, ScopeAdmin); !ok {
+			return
+		}
+		if r.Method == http.MethodPost && path == "/admin/invalidate" {
+			h.serveInvalidate(w, r)
+			return
+		}
+		writeError(w, r, http.StatusNotFound, "Not Found")
+		return
+	}
+	key, ok := h.authenticate(w, r, ScopeLookup)
+	if !ok {
+		return
+	}
+	// Bulk requests are charged once their queries are counted.
+	bulk := r.Method == http.MethodPost &&
+		(path == "/batch" || path == "/profiles/minecraft" || path == "/minecraft/profile/lookup/bulk/byname")
+	if !bulk && !h.allow(w, r, key, 1) {
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/users/profiles/minecraft/"):
+		h.serveUUID(w, r, strings.TrimPrefix(path, "/users/profiles/minecraft/"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/minecraft/profile/lookup/name/"):
+		h.serveUUID(w, r, strings.TrimPrefix(path, "/minecraft/profile/lookup/name/"))
+	case r.Method == http.MethodPost && (path == "/profiles/minecraft" || path == "/minecraft/profile/lookup/bulk/byname"):
+		h.serveUUIDs(w, r, key)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/user/profiles/") && strings.HasSuffix(path, "/names"):
+		h.serveNameHistory(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/user/profiles/"), "/names"))
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/session/minecraft/profile/"):
+		h.serveProfile(w, r, strings.TrimPrefix(path, "/session/minecraft/profile/"))
+	case r.Method == http.MethodPost && path == "/batch":
+		h.serveBatch(w, r, key)
+	case r.Method == http.MethodGet && path == "/events":
+		h.serveEvents(w, r)
+	case r.Method == http.MethodGet && h.Renders && strings.HasPrefix(path, "/render/"):
+		h.serveRender(w, r, strings.TrimPrefix(path, "/render/"))
+	default:
+		writeError(w, r, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (h *Handler) serveUUID(w http.ResponseWriter, r *http.Request, name string) {
+	uuid, name, err := h.Client.GetUUIDContext(r.Context(), name)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, mojangProfile{ID: uuid, Name: name})
+}
+
+func (h *Handler) serveUUIDs(w http.ResponseWriter, r *http.Request, key *APIKey) {
+	var names []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&names); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(names) > maxBulkNames {
+		writeError(w, r, http.StatusBadRequest, fmt.Sprintf("Not more than %d profile names per call are allowed", maxBulkNames))
+		return
+	}
+	if !h.allow(w, r, key, len(names)) {
+		return
+	}
+	results := h.Client.GetUUIDsContext(r.Context(), names)
+	// Mojang rejects the whole request if any name is invalid.
+	for _, res := range results {
+		if errors.Is(res.Err, mcaccutils.ErrInvalidName) {
+			writeLookupError(w, r, res.Err)
+			return
+		}
+	}
+	found := make([]mojangProfile, 0, len(names))
+	seen := make(map[string]bool)
+	for _, res := range results {
+		if errors.Is(res.Err, mcaccutils.ErrPlayerNotFound) || seen[res.UUID] {
+			continue
+		}
+		if res.Err != nil {
+			writeLookupError(w, r, res.Err)
+			return
+		}
+		seen[res.UUID] = true
+		found = append(found, mojangProfile{ID: res.UUID, Name: res.Name})
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+func (h *Handler) serveNameHistory(w http.ResponseWriter, r *http.Request, uuid string) {
+	history, err := h.Client.GetNameHistory(uuid)
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+	type entry struct {
+		Name        string `json:"name"`
+		ChangedToAt int64  `json:"changedToAt,omitempty"`
+	}
+	entries := make([]entry, len(history))
+	for i, n := range history {
+		entries[i].Name = n.Name
+		if !n.ChangedAt.IsZero() {
+			entries[i].ChangedToAt = n.ChangedAt.UnixMilli()
+		}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (h *Handler) serveProfile(w http.ResponseWriter, r *http.Request, uuid string) {
+	get := h.Client.GetProfileContext
+	if r.URL.Query().Get("unsigned") == "false" {
+		get = h.Client.GetSignedProfileContext
+	}
+	p, err := get(r.Context(), uuid)
+	if errors.Is(err, mcaccutils.ErrPlayerNotFound) {
+		// The session server answers unknown UUIDs with no content.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		writeLookupError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// writeJSON writes v as the JSON body of a response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format of the Mojang API.
+func writeError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeJSON(w, status, struct {
+		Path         string `json:"path"`
+		ErrorMessage string `json:"errorMessage"`
+	}{r.URL.Path, msg})
+}
+
+// writeLookupError writes the response for a failed lookup.
+func writeLookupError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, mcaccutils.ErrInvalidName):
+		writeError(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, mcaccutils.ErrPlayerNotFound):
+		writeError(w, r, http.StatusNotFound, "Couldn't find any profile")
+	case errors.Is(err, mcaccutils.ErrEndpointRetired):
+		writeError(w, r, http.StatusGone, err.Error())
+	case errors.Is(err, mcaccutils.ErrRateLimited):
+		// Pass on how long Mojang asked clients to wait.
+		var se *mcaccutils.StatusError
+		if errors.As(err, &se) && se.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(se.RetryAfter.Seconds()))))
+		}
+		writeError(w, r, http.StatusTooManyRequests, err.Error())
+	default:
+		writeError(w, r, http.StatusBadGateway, fmt.Sprintf("Upstream lookup failed: %v", err))
+	}
+}
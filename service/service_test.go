@@ -0,0 +1,23 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+func TestBulkLookupCapped(t *testing.T) {
+	h := &Handler{Client: mcaccutils.NewClient(mcaccutils.DefaultConfig())}
+	names := `["a!","b!","c!","d!","e!","f!","g!","h!","i!","j!","k!"]`
+	for _, path := range []string{"/profiles/minecraft", "/minecraft/profile/lookup/bulk/byname"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(names))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Not more than 10") {
+			t.Errorf("%s with 11 names got status %d, %s, want 400", path, rec.Code, rec.Body)
+		}
+	}
+}
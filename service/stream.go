@@ -0,0 +1,88 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// streamBuffer is the number of events kept for a slow stream consumer
+// before further events are dropped.
+const streamBuffer = 64
+
+// heartbeatInterval is how often an idle stream is sent a comment, so
+// proxies do not close it.
+const heartbeatInterval = 30 * time.Second
+
+// A StreamEvent is an event sent by the event stream.
+type StreamEvent struct {
+	// UUID identifies the player the event is about.
+	UUID string `json:"id"`
+	// Name is the current name of the player, for name_changed events.
+	Name string `json:"name,omitempty"`
+	// OldName is the name the player had before, for name_changed events.
+	OldName string `json:"oldName,omitempty"`
+	// SkinHash is the hash of the new skin, for skin_changed events.
+	SkinHash string `json:"skinHash,omitempty"`
+	// Time is when the change was found.
+	Time time.Time `json:"time"`
+}
+
+// streamed reports whether events of type t are sent by the event stream.
+func streamed(t mcaccutils.EventType) bool {
+	return t == mcaccutils.EventNameChanged || t == mcaccutils.EventSkinChanged
+}
+
+// serveEvents streams the name and skin changes found by the client as
+// server-sent events until the consumer goes away. Changes are only found
+// while the refresher or a skin watcher of the client is running.
+func (h *Handler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, http.StatusInternalServerError, "Streaming unsupported")
+		return
+	}
+	events := make(chan mcaccutils.Event, streamBuffer)
+	unsubscribe := h.Client.Subscribe(func(e mcaccutils.Event) {
+		if !streamed(e.Type) {
+			return
+		}
+		select {
+		case events <- e:
+		default:
+			// The consumer is not keeping up, and must not hold up the
+			// client.
+		}
+	})
+	defer unsubscribe()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case e := <-events:
+			data, err := json.Marshal(StreamEvent{
+				UUID:     e.UUID,
+				Name:     e.Name,
+				OldName:  e.OldName,
+				SkinHash: e.SkinHash,
+				Time:     e.Time,
+			})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,99 @@
+package mcaccutils
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// A LookupService selects the API a Client uses to look up UUIDs by name.
+type LookupService int
+
+const (
+	// LookupAccountAPI looks up UUIDs with the account API at
+	// Config.APIURL.
+	LookupAccountAPI LookupService = iota
+	// LookupMinecraftServices looks up UUIDs with the Minecraft services API
+	// at Config.ServicesURL.
+	LookupMinecraftServices
+	// LookupFallback looks up UUIDs with the account API, and retries
+	// lookups which fail with the Minecraft services API.
+	LookupFallback
+	// LookupFailover looks up UUIDs with the account API until it fails
+	// Config.FailoverThreshold times in a row, then switches to the
+	// Minecraft services API until the account API recovers. See
+	// Client.Failover.
+	LookupFailover
+)
+
+// The paths of the lookup endpoints of the Minecraft services API.
+const (
+	servicesNamePath = "/minecraft/profile/lookup/name/"
+	servicesBulkPath = "/minecraft/profile/lookup/bulk/byname"
+)
+
+// bulkEndpoint returns the URL of the bulk lookup endpoint the client uses
+// first.
+func (c *Client) bulkEndpoint() string {
+	if c.usingServices() {
+		return c.servicesURL() + servicesBulkPath
+	}
+	return c.apiURL() + "/profiles/minecraft"
+}
+
+// usingServices reports whether UUID lookups currently go to the Minecraft
+// services API first.
+func (c *Client) usingServices() bool {
+	switch c.Config().LookupService {
+	case LookupMinecraftServices:
+		return true
+	case LookupFailover:
+		return c.failover.isDown()
+	}
+	return false
+}
+
+// fetchUUIDFromServices looks up the player with the given lowercased name
+// using the single lookup endpoint of the Minecraft services API, bypassing
+// the cache.
+func (c *Client) fetchUUIDFromServices(ctx context.Context, n string) (p *playerCacheData, err error) {
+	defer func() { c.metrics.countError(err) }()
+	base := c.servicesURL()
+	if err := c.checkEndpoint(FeatureUUIDLookup, base); err != nil {
+		return nil, err
+	}
+	endpoint := base + servicesNamePath + n
+	resp, err := c.get(ctx, endpoint, n)
+	if err != nil {
+		return nil, lookupError(endpoint, n, err)
+	}
+	defer resp.Body.Close()
+	// Read out the body.
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, lookupError(endpoint, n, err)
+	}
+	if err := c.checkRetired(FeatureUUIDLookup, base, resp); err != nil {
+		return nil, err
+	}
+	// Unknown names get a 404 with an error message.
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent {
+		return nil, ErrPlayerNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(endpoint, n, statusError(resp))
+	}
+	// Decode the JSON
+	var decResp mojangNameResponseProfile
+	if err := c.decodeJSON(endpoint, body, &decResp); err != nil {
+		return nil, err
+	}
+	if decResp.UUID == "" {
+		return nil, ErrPlayerNotFound
+	}
+	return &playerCacheData{
+		UUID:     strings.Replace(decResp.UUID, "-", "", -1),
+		Username: decResp.Name,
+	}, nil
+}
@@ -0,0 +1,232 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// A SessionManager holds the sessions of several accounts, such as the bot or
+// alt accounts of a tool, and refreshes them. One account is current at a
+// time, which the tool can switch between. It is safe for concurrent use.
+//
+// Sessions handed out by the manager are shared, so they must not be
+// modified; refreshing an account replaces its session rather than changing
+// it.
+type SessionManager struct {
+	// Client is used to refresh sessions. Nil means the default client.
+	Client *Client
+	// ClientID is the Microsoft application ID used to refresh sessions from
+	// LoginWithDeviceCode, which have a RefreshToken. Other sessions are
+	// refreshed with the authentication server.
+	ClientID string
+	// Store, if set, saves the sessions added to the manager and their
+	// refreshed replacements, and accounts which are not held yet are
+	// loaded from it.
+	Store TokenStore
+
+	mu       sync.Mutex
+	accounts map[string]*managedAccount
+	current  string
+}
+
+// managedAccount is an account held by a SessionManager.
+type managedAccount struct {
+	// refreshMu is held while the session is refreshed, so concurrent
+	// refreshes of the account are only sent once.
+	refreshMu sync.Mutex
+	// session is guarded by the mutex of the manager.
+	session *Session
+}
+
+// client returns the client sessions are refreshed with.
+func (m *SessionManager) client() *Client {
+	if m.Client != nil {
+		return m.Client
+	}
+	return defaultClient
+}
+
+// account returns the held account with the specified name, loading it from
+// the store if it is not held yet. It must be called with m.mu held.
+func (m *SessionManager) account(name string) (*managedAccount, error) {
+	if a, ok := m.accounts[name]; ok {
+		return a, nil
+	}
+	if m.Store == nil {
+		return nil, fmt.Errorf("%w for %q", ErrNoSession, name)
+	}
+	s, err := m.Store.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.hold(name, s), nil
+}
+
+// hold makes s the session of the account with the specified name. It must be
+// called with m.mu held.
+func (m *SessionManager) hold(name string, s *Session) *managedAccount {
+	if m.accounts == nil {
+		m.accounts = make(map[string]*managedAccount)
+	}
+	a, ok := m.accounts[name]
+	if !ok {
+		a = new(managedAccount)
+		m.accounts[name] = a
+	}
+	a.session = s
+	if m.current == "" {
+		m.current = name
+	}
+	return a
+}
+
+// Add adds the account with the specified name and session, replacing any
+// session it had, and saves it to the store. The first account added becomes
+// the current one.
+func (m *SessionManager) Add(account string, s *Session) error {
+	m.mu.Lock()
+	m.hold(account, s)
+	m.mu.Unlock()
+	if m.Store != nil {
+		return m.Store.Save(account, s)
+	}
+	return nil
+}
+
+// Remove removes the account with the specified name from the manager and
+// from the store. If it was the current account, there is no current account
+// until Switch is called.
+func (m *SessionManager) Remove(account string) error {
+	m.mu.Lock()
+	delete(m.accounts, account)
+	if m.current == account {
+		m.current = ""
+	}
+	m.mu.Unlock()
+	if m.Store != nil {
+		return m.Store.Delete(account)
+	}
+	return nil
+}
+
+// Accounts returns the names of the accounts held by the manager, sorted.
+// Accounts in the store which have not been used are not included.
+func (m *SessionManager) Accounts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.accounts))
+	for name := range m.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Switch makes the account with the specified name the current one, loading
+// it from the store if needed. It returns an error matching ErrNoSession if
+// the manager has no session for the account.
+func (m *SessionManager) Switch(account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.account(account); err != nil {
+		return err
+	}
+	m.current = account
+	return nil
+}
+
+// Current returns the name and session of the current account. It returns an
+// error matching ErrNoSession if there is no current account.
+func (m *SessionManager) Current() (account string, s *Session, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current == "" {
+		return "", nil, fmt.Errorf("%w: no current account", ErrNoSession)
+	}
+	return m.current, m.accounts[m.current].session, nil
+}
+
+// Session returns the session of the account with the specified name,
+// loading it from the store if needed. It returns an error matching
+// ErrNoSession if the manager has no session for the account.
+func (m *SessionManager) Session(account string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, err := m.account(account)
+	if err != nil {
+		return nil, err
+	}
+	return a.session, nil
+}
+
+// Refresh replaces the session of the account with the specified name with a
+// fresh one, saving it to the store, and returns it. Sessions with a
+// Microsoft refresh token are refreshed with RefreshMicrosoft, and others
+// with Refresh.
+//
+// Refreshing invalidates the old access token, so concurrent calls for the
+// same account send a single refresh, and every caller gets its result.
+func (m *SessionManager) Refresh(ctx context.Context, account string) (*Session, error) {
+	m.mu.Lock()
+	a, err := m.account(account)
+	var old *Session
+	if err == nil {
+		old = a.session
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	a.refreshMu.Lock()
+	defer a.refreshMu.Unlock()
+	m.mu.Lock()
+	s := a.session
+	m.mu.Unlock()
+	if s != old {
+		// Another caller refreshed the session while this one waited.
+		return s, nil
+	}
+	c := m.client()
+	var ns *Session
+	if s.RefreshToken != "" {
+		if m.ClientID == "" {
+			return nil, errors.New("mcaccutils: refreshing a Microsoft session needs SessionManager.ClientID")
+		}
+		ns, err = c.RefreshMicrosoft(ctx, m.ClientID, s)
+	} else {
+		ns, err = c.Refresh(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: refreshing session of %q: %w", account, err)
+	}
+	m.mu.Lock()
+	// The account may have been replaced or removed during the refresh, in
+	// which case the new session is only returned.
+	kept := m.accounts[account] == a && a.session == s
+	if kept {
+		a.session = ns
+	}
+	m.mu.Unlock()
+	if kept && m.Store != nil {
+		if err := m.Store.Save(account, ns); err != nil {
+			return ns, err
+		}
+	}
+	return ns, nil
+}
+
+// RefreshAll refreshes the session of every account held by the manager, as
+// Refresh does. It carries on past failures, returning the error of the
+// first.
+func (m *SessionManager) RefreshAll(ctx context.Context) error {
+	var first error
+	for _, account := range m.Accounts() {
+		if _, err := m.Refresh(ctx, account); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
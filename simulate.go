@@ -0,0 +1,114 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// simulatedBody is the body of the responses rejected by a Simulation, which
+// is the one sent by the Mojang API.
+const simulatedBody = `{"error":"TooManyRequestsException","errorMessage":"The client has sent too many requests within a certain amount of time"}`
+
+// A Simulation describes the throttling a Client with Config.Simulation set
+// pretends the API applies to it, so programs can check how they cope with
+// rate limiting without provoking the real API. Rejected requests never
+// leave the client: they get a 429 Too Many Requests response like those of
+// the API, which the client handles as it would a real one, publishing
+// EventRateLimited and slowing down its adaptive rate limit. Requests which
+// are let through are sent as normal.
+type Simulation struct {
+	// RateLimit is the number of requests the simulated API allows per
+	// RateLimitPeriod before rejecting them. It is usually set far below
+	// the real limit. Zero means no limit.
+	RateLimit int
+	// RateLimitPeriod is the period of RateLimit. Zero means one minute.
+	RateLimitPeriod time.Duration
+	// FailureRate is the probability, from 0 to 1, of a request within the
+	// limit being rejected anyway, as the real API sometimes does.
+	FailureRate float64
+	// RetryAfter, if not zero, is sent in the Retry-After header of the
+	// rejections.
+	RetryAfter time.Duration
+	// Seed seeds the random failures, so runs can be repeated. Zero means a
+	// seed based on the current time.
+	Seed int64
+}
+
+// simulation is the http.RoundTripper applying a Simulation.
+type simulation struct {
+	sim     Simulation
+	base    http.RoundTripper
+	limiter *limiter
+
+	// mu guards rand, which is not safe for concurrent use.
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// simulate returns base wrapped to apply sim, using clock for its rate limit,
+// or base itself if sim is nil.
+func simulate(sim *Simulation, clock Clock, base http.RoundTripper) http.RoundTripper {
+	if sim == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	per := sim.RateLimitPeriod
+	if per <= 0 {
+		per = time.Minute
+	}
+	seed := sim.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &simulation{
+		sim:     *sim,
+		base:    base,
+		limiter: newLimiter(clock, sim.RateLimit, per, false, 0),
+		rand:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *simulation) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !s.limiter.Allow() || s.fail() {
+		return s.reject(req), nil
+	}
+	return s.base.RoundTrip(req)
+}
+
+// fail reports whether a request within the limit should be rejected.
+func (s *simulation) fail() bool {
+	if s.sim.FailureRate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < s.sim.FailureRate
+}
+
+// reject returns the response rejecting req.
+func (s *simulation) reject(req *http.Request) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	if s.sim.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int((s.sim.RetryAfter+time.Second-1)/time.Second)))
+	}
+	return &http.Response{
+		Status:        "429 Too Many Requests",
+		StatusCode:    http.StatusTooManyRequests,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(simulatedBody))),
+		ContentLength: int64(len(simulatedBody)),
+		Request:       req,
+	}
+}
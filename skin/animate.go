@@ -0,0 +1,88 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"time"
+)
+
+// rotation renders frames images of the head of s, each size pixels square,
+// turning it a full circle.
+func rotation(s image.Image, size, frames int) []*image.NRGBA {
+	imgs := make([]*image.NRGBA, frames)
+	for i := range imgs {
+		imgs[i] = HeadTurned(s, size, 360*float64(i)/float64(frames))
+	}
+	return imgs
+}
+
+// Spritesheet renders the head of s turning a full circle in frames steps,
+// laid out left to right in a single image frames*size pixels wide and size
+// pixels high.
+func Spritesheet(s image.Image, size, frames int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size*frames, size))
+	for i, img := range rotation(s, size, frames) {
+		draw.Draw(dst, img.Bounds().Add(image.Pt(i*size, 0)), img, image.Point{}, draw.Src)
+	}
+	return dst
+}
+
+// RotatingGIF renders an animation of the head of s turning a full circle in
+// frames steps, showing each frame for delay. The GIF loops forever, and the
+// background is transparent.
+//
+// GIFs are limited to 256 colours, so skins with more colours than that are
+// reduced to the web safe palette.
+func RotatingGIF(s image.Image, size, frames int, delay time.Duration) *gif.GIF {
+	imgs := rotation(s, size, frames)
+	pal := gifPalette(imgs)
+	g := &gif.GIF{}
+	for _, img := range imgs {
+		p := image.NewPaletted(img.Bounds(), pal)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				c := img.NRGBAAt(x, y)
+				// GIF transparency is all or nothing.
+				if c.A < 0x80 {
+					continue
+				}
+				c.A = 0xff
+				p.SetColorIndex(x, y, uint8(pal.Index(c)))
+			}
+		}
+		g.Image = append(g.Image, p)
+		g.Delay = append(g.Delay, int(delay/(10*time.Millisecond)))
+		g.Disposal = append(g.Disposal, gif.DisposalBackground)
+	}
+	return g
+}
+
+// gifPalette returns a palette for imgs with transparency at index zero,
+// holding every opaque colour used if there are few enough.
+func gifPalette(imgs []*image.NRGBA) color.Palette {
+	pal := color.Palette{color.Transparent}
+	seen := make(map[color.NRGBA]bool)
+	for _, img := range imgs {
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := img.NRGBAAt(x, y)
+				if c.A < 0x80 {
+					continue
+				}
+				c.A = 0xff
+				if !seen[c] {
+					seen[c] = true
+					pal = append(pal, c)
+				}
+				if len(pal) > 256 {
+					return append(color.Palette{color.Transparent}, palette.WebSafe...)
+				}
+			}
+		}
+	}
+	return pal
+}
@@ -0,0 +1,154 @@
+package skin
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// DiskCache keeps downloaded skin images in a directory, keyed by texture
+// hash, so they survive restarts. Texture images never change, so entries
+// never expire, but the least recently used ones are removed once the cache
+// grows over MaxBytes.
+//
+// The zero value is not usable; Dir must be set. DiskCache is safe for
+// concurrent use, but the directory must not be shared with another
+// DiskCache.
+type DiskCache struct {
+	// Dir is the directory the images are stored in. It is created if it
+	// does not exist.
+	Dir string
+	// MaxBytes is the total size the images may use. Zero means no limit.
+	MaxBytes int64
+
+	mu sync.Mutex
+}
+
+// path returns the file an image is stored in, or an empty string if hash
+// could not have come from a texture URL.
+func (d *DiskCache) path(hash string) string {
+	if hash == "" || hash != filepath.Base(hash) || hash == "." || hash == ".." {
+		return ""
+	}
+	return filepath.Join(d.Dir, hash+".png")
+}
+
+// Get returns the image stored under hash, marking it as recently used.
+func (d *DiskCache) Get(hash string) (b []byte, found bool) {
+	p := d.path(hash)
+	if p == "" {
+		return nil, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	return b, true
+}
+
+// Put stores the image b under hash, then removes the least recently used
+// images until the cache fits in MaxBytes.
+func (d *DiskCache) Put(hash string, b []byte) error {
+	p := d.path(hash)
+	if p == "" {
+		return fmt.Errorf("skin: invalid texture hash %q", hash)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := os.MkdirAll(d.Dir, 0755); err != nil {
+		return fmt.Errorf("skin: creating cache directory: %w", err)
+	}
+	// Write to a temporary file first, so a crash never leaves a truncated
+	// image behind.
+	tmp, err := ioutil.TempFile(d.Dir, ".tmp-")
+	if err != nil {
+		return fmt.Errorf("skin: caching %s: %w", hash, err)
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("skin: caching %s: %w", hash, err)
+	}
+	return d.evict()
+}
+
+// evict removes the least recently used images until the cache fits in
+// MaxBytes. d.mu must be held.
+func (d *DiskCache) evict() error {
+	if d.MaxBytes <= 0 {
+		return nil
+	}
+	entries, err := ioutil.ReadDir(d.Dir)
+	if err != nil {
+		return fmt.Errorf("skin: reading cache directory: %w", err)
+	}
+	var total int64
+	var files []os.FileInfo
+	for _, e := range entries {
+		if e.Mode().IsRegular() && filepath.Ext(e.Name()) == ".png" {
+			total += e.Size()
+			files = append(files, e)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, f := range files {
+		if total <= d.MaxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(d.Dir, f.Name())); err != nil {
+			return fmt.Errorf("skin: evicting %s: %w", f.Name(), err)
+		}
+		total -= f.Size()
+	}
+	return nil
+}
+
+// Fetch is like the package level Fetch, but reads the skin image from the
+// cache if it is there, and stores it in the cache otherwise. A nil
+// *DiskCache downloads every image.
+func (d *DiskCache) Fetch(c *mcaccutils.Client, uuid string) (s image.Image, t *mcaccutils.Texture, err error) {
+	t, err = skinTexture(c, uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+	var b []byte
+	found := false
+	if d != nil {
+		b, found = d.Get(t.Hash())
+	}
+	if !found {
+		if b, err = c.GetTexture(t); err != nil {
+			return nil, nil, err
+		}
+	}
+	s, err = png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, nil, fmt.Errorf("skin: decoding skin of %s: %w", uuid, err)
+	}
+	if d != nil && !found {
+		// A failure to cache the image only costs a download next time.
+		d.Put(t.Hash(), b)
+	}
+	return s, t, nil
+}
@@ -0,0 +1,32 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+)
+
+// Face renders the front of the head of the skin s with the hat on top, as a
+// flat image size pixels square. Skin pixels are scaled up with nearest
+// neighbour sampling, so they stay sharp.
+func Face(s image.Image, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	layers := []Layer{Base}
+	if !legacyOpaque(s, rect(s, 32, 0, 32, 16)) {
+		layers = append(layers, Overlay)
+	}
+	for _, l := range layers {
+		r := Region(Classic, Head, l, Front)
+		src := rect(s, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				c := color.NRGBAModel.Convert(s.At(src.Min.X+x*src.Dx()/size, src.Min.Y+y*src.Dy()/size)).(color.NRGBA)
+				if l == Base {
+					// The base layer is always drawn opaque.
+					c.A = 0xff
+				}
+				dst.SetNRGBA(x, y, over(dst.NRGBAAt(x, y), c))
+			}
+		}
+	}
+	return dst
+}
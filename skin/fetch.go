@@ -0,0 +1,34 @@
+package skin
+
+import (
+	"errors"
+	"image"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// ErrNoSkin is returned by Fetch for players using one of the default skins.
+var ErrNoSkin = errors.New("skin: player has no custom skin")
+
+// Fetch downloads the skin of the player with the specified UUID using the
+// client c, returning the skin image and the texture it was read from.
+func Fetch(c *mcaccutils.Client, uuid string) (s image.Image, t *mcaccutils.Texture, err error) {
+	var d *DiskCache
+	return d.Fetch(c, uuid)
+}
+
+// skinTexture returns the skin texture of the player with the specified UUID.
+func skinTexture(c *mcaccutils.Client, uuid string) (*mcaccutils.Texture, error) {
+	p, err := c.GetProfile(uuid)
+	if err != nil {
+		return nil, err
+	}
+	textures, err := p.Textures()
+	if err != nil {
+		return nil, err
+	}
+	if textures.Skin == nil {
+		return nil, ErrNoSkin
+	}
+	return textures.Skin, nil
+}
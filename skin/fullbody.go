@@ -0,0 +1,75 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+)
+
+// guessModel returns the model the skin s, in the modern layout, appears to
+// be made for. Slim skins leave the last two columns of the front and back of
+// the right arm transparent, while classic skins are opaque there.
+func guessModel(s image.Image) Model {
+	r := rect(s, 54, 20, 2, 12)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if _, _, _, a := s.At(x, y).RGBA(); a != 0 {
+				return Classic
+			}
+		}
+	}
+	return Slim
+}
+
+// FullBody renders the front of the whole player, with the overlay on top,
+// as a flat image size pixels tall and half as wide. The model is guessed
+// from the skin, as slim skins leave the unused part of the arm texture
+// transparent. Legacy skins are converted with ConvertLegacy first.
+func FullBody(s image.Image, size int) *image.NRGBA {
+	hatless := legacyOpaque(s, rect(s, 32, 0, 32, 16))
+	s = ConvertLegacy(s)
+	m := guessModel(s)
+	armWidth := 4
+	if m == Slim {
+		armWidth = 3
+	}
+	// Lay the parts out in skin pixels, with the player facing the viewer,
+	// so their right arm and leg are on the left.
+	parts := []struct {
+		p    Part
+		x, y int
+	}{
+		{Head, 4, 0},
+		{Body, 4, 8},
+		{RightArm, 4 - armWidth, 8},
+		{LeftArm, 12, 8},
+		{RightLeg, 4, 20},
+		{LeftLeg, 8, 20},
+	}
+	n := scale(s)
+	canvas := image.NewNRGBA(image.Rect(0, 0, 16*n, 32*n))
+	for _, l := range []Layer{Base, Overlay} {
+		for _, part := range parts {
+			if l == Overlay && part.p == Head && hatless {
+				continue
+			}
+			src := Region(m, part.p, l, Front)
+			r := rect(s, src.Min.X, src.Min.Y, src.Dx(), src.Dy())
+			for y := 0; y < r.Dy(); y++ {
+				for x := 0; x < r.Dx(); x++ {
+					c := color.NRGBAModel.Convert(s.At(r.Min.X+x, r.Min.Y+y)).(color.NRGBA)
+					if l == Base {
+						// The base layer is always drawn opaque.
+						c.A = 0xff
+					}
+					dx, dy := part.x*n+x, part.y*n+y
+					canvas.SetNRGBA(dx, dy, over(canvas.NRGBAAt(dx, dy), c))
+				}
+			}
+		}
+	}
+	w := size / 2
+	if w < 1 {
+		w = 1
+	}
+	return Scale(canvas, w, size)
+}
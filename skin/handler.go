@@ -0,0 +1,178 @@
+package skin
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// uuidPattern matches UUIDs, with or without dashes.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+// Handler is an http.Handler serving the faces of players as PNG images. It
+// answers requests for /{query} and /{query}/{size}, where query is a
+// username or a UUID and size is the width of the image in pixels, so it is
+// usually mounted with http.StripPrefix. Either may be followed by the
+// extension of one of the Formats of the handler, such as /Notch/64.png, to
+// choose the format of the image.
+//
+//	h := &skin.Handler{Client: mcaccutils.NewClient(mcaccutils.DefaultConfig())}
+//	http.Handle("/avatar/", http.StripPrefix("/avatar", h))
+type Handler struct {
+	// Client is used to look up players and download their skins. It must
+	// not be nil.
+	Client *mcaccutils.Client
+
+	// DefaultSize is the size of images requested without one. Zero means 64
+	// pixels.
+	DefaultSize int
+	// MaxSize is the largest size which may be requested. Zero means 512
+	// pixels.
+	MaxSize int
+
+	// MaxAge is how long clients may cache the images for. Zero means one
+	// hour.
+	MaxAge time.Duration
+
+	// Default, if set, is the skin used for players who have no custom skin
+	// or do not exist. Otherwise such requests get a 404 response.
+	Default image.Image
+
+	// Render draws the image of size pixels for the skin s. If it is nil,
+	// Face is used; Head3D is a common alternative.
+	Render func(s image.Image, size int) *image.NRGBA
+
+	// Background, if set, is drawn behind the image, which is otherwise left
+	// transparent.
+	Background color.Color
+
+	// Formats maps file extensions to the encoders for the formats which may
+	// be requested. PNG is always available under "png", and is used for
+	// requests without an extension. Other formats need an encoder from
+	// another package, such as the WebP encoder of the skin/webp package.
+	Formats map[string]Encoder
+
+	// Cache, if set, keeps the downloaded skins on disk.
+	Cache *DiskCache
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) > 2 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	// Names and UUIDs never contain dots, so anything after one is the
+	// extension.
+	last := len(parts) - 1
+	ext := "png"
+	if i := strings.LastIndex(parts[last], "."); i >= 0 {
+		parts[last], ext = parts[last][:i], parts[last][i+1:]
+	}
+	enc, ok := h.Formats[ext]
+	if !ok && ext == "png" {
+		enc, ok = PNG, true
+	}
+	if !ok {
+		http.Error(w, "unsupported format", http.StatusNotFound)
+		return
+	}
+	size := h.DefaultSize
+	if size == 0 {
+		size = 64
+	}
+	if len(parts) == 2 {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 1 || n > h.maxSize() {
+			http.Error(w, fmt.Sprintf("size must be between 1 and %d", h.maxSize()), http.StatusBadRequest)
+			return
+		}
+		size = n
+	}
+	s, etag, err := h.skin(parts[0])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	etag = fmt.Sprintf("%q", etag+"-"+strconv.Itoa(size)+"."+ext)
+	maxAge := h.MaxAge
+	if maxAge == 0 {
+		maxAge = time.Hour
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	render := h.Render
+	if render == nil {
+		render = Face
+	}
+	img := render(s, size)
+	if h.Background != nil {
+		img = Flatten(img, h.Background)
+	}
+	w.Header().Set("Content-Type", enc.ContentType)
+	enc.Encode(w, img)
+}
+
+// writeError answers a request whose skin could not be fetched with the
+// status matching err.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, mcaccutils.ErrInvalidName):
+		// Checked first, as invalid names also match ErrPlayerNotFound.
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, mcaccutils.ErrPlayerNotFound) || errors.Is(err, ErrNoSkin):
+		http.NotFound(w, r)
+	case errors.Is(err, mcaccutils.ErrRateLimited):
+		// Pass on how long Mojang asked clients to wait.
+		var se *mcaccutils.StatusError
+		if errors.As(err, &se) && se.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(se.RetryAfter.Seconds()))))
+		}
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	default:
+		http.Error(w, "looking up player failed", http.StatusBadGateway)
+	}
+}
+
+// maxSize returns the largest size which may be requested.
+func (h *Handler) maxSize() int {
+	if h.MaxSize == 0 {
+		return 512
+	}
+	return h.MaxSize
+}
+
+// skin returns the skin of the player matching query, and a tag identifying
+// it.
+func (h *Handler) skin(query string) (s image.Image, tag string, err error) {
+	uuid := query
+	if !uuidPattern.MatchString(query) {
+		uuid, _, err = h.Client.GetUUID(query)
+	}
+	if err == nil {
+		var t *mcaccutils.Texture
+		s, t, err = h.Cache.Fetch(h.Client, uuid)
+		if err == nil {
+			return s, t.Hash(), nil
+		}
+	}
+	notFound := errors.Is(err, mcaccutils.ErrPlayerNotFound) && !errors.Is(err, mcaccutils.ErrInvalidName)
+	if h.Default != nil && (notFound || errors.Is(err, ErrNoSkin)) {
+		return h.Default, "default", nil
+	}
+	return nil, "", err
+}
@@ -0,0 +1,130 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// A face is one side of a cube to be drawn in the isometric projection. The
+// texture region tex is mapped onto the parallelogram starting at origin with
+// the edges u, along the width of the texture, and v, along its height.
+type face struct {
+	tex    image.Rectangle
+	origin [2]float64
+	u, v   [2]float64
+	shade  float64
+}
+
+// project returns the projection of the point x, y, z of a unit cube, turned
+// by angle radians about its vertical axis and seen from above at the
+// isometric angle. Without turning, x runs to the right of the image, z
+// towards the viewer, and y upwards. The centre of the cube is projected to
+// the origin.
+func project(x, y, z, angle float64) [2]float64 {
+	x, y, z = x-0.5, y-0.5, z-0.5
+	sin, cos := math.Sincos(angle)
+	rx := x*cos - z*sin
+	rz := x*sin + z*cos
+	return [2]float64{rx * math.Sqrt(6) / 2, rz*math.Sqrt(2)/2 - y}
+}
+
+// cubeSides describes the sides of the head cube drawn by the renderers: the
+// corner of the cube where the texture starts, the corners its width and
+// height run to, the outward normal in the x, z plane, and the shading.
+var cubeSides = []struct {
+	side             Side
+	origin, u, v     [3]float64
+	normalX, normalZ float64
+	shade            float64
+}{
+	{Front, [3]float64{0, 1, 1}, [3]float64{1, 1, 1}, [3]float64{0, 0, 1}, 0, 1, 0.9},
+	{Left, [3]float64{1, 1, 1}, [3]float64{1, 1, 0}, [3]float64{1, 0, 1}, 1, 0, 0.8},
+	{Back, [3]float64{1, 1, 0}, [3]float64{0, 1, 0}, [3]float64{1, 0, 0}, 0, -1, 0.9},
+	{Right, [3]float64{0, 1, 0}, [3]float64{0, 1, 1}, [3]float64{0, 0, 0}, -1, 0, 0.8},
+}
+
+// cubeFaces returns the visible faces of a cube textured with the layer l of
+// the head of s, enlarged about its centre by grow and turned by angle
+// radians. The faces are returned in drawing order.
+func cubeFaces(s image.Image, l Layer, grow, angle float64) []face {
+	p := func(c [3]float64) [2]float64 {
+		g := func(t float64) float64 { return 0.5 + (t-0.5)*grow }
+		return project(g(c[0]), g(c[1]), g(c[2]), angle)
+	}
+	sub := func(a, b [2]float64) [2]float64 { return [2]float64{a[0] - b[0], a[1] - b[1]} }
+	tex := func(side Side) image.Rectangle {
+		r := Region(Classic, Head, l, side)
+		return rect(s, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	}
+	sin, cos := math.Sincos(angle)
+	var faces []face
+	for _, c := range cubeSides {
+		// Only sides facing the viewer are visible.
+		if c.normalX*sin+c.normalZ*cos <= 0 {
+			continue
+		}
+		o := p(c.origin)
+		faces = append(faces, face{tex: tex(c.side), origin: o, u: sub(p(c.u), o), v: sub(p(c.v), o), shade: c.shade})
+	}
+	top := p([3]float64{0, 1, 0})
+	return append(faces, face{
+		tex: tex(Top), origin: top, shade: 1,
+		u: sub(p([3]float64{1, 1, 0}), top),
+		v: sub(p([3]float64{0, 1, 1}), top),
+	})
+}
+
+// Head3D renders the head of the skin s as the classic isometric cube,
+// showing the top, front and left side of the head with the overlay layer on
+// top. The result is size pixels square, with a transparent background.
+func Head3D(s image.Image, size int) *image.NRGBA {
+	return HeadTurned(s, size, 45)
+}
+
+// HeadTurned renders the head of the skin s as Head3D does, but turned by
+// angle degrees to its right, so at zero degrees the head faces the viewer,
+// and at 45 degrees it is the classic isometric view.
+func HeadTurned(s image.Image, size int, angle float64) *image.NRGBA {
+	rad := angle * math.Pi / 180
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	// The overlay is half a skin pixel larger than the head on each side.
+	const grow = 9.0 / 8
+	drawFaces(dst, s, cubeFaces(s, Base, 1, rad))
+	if !legacyOpaque(s, rect(s, 32, 0, 32, 16)) {
+		drawFaces(dst, s, cubeFaces(s, Overlay, grow, rad))
+	}
+	return dst
+}
+
+// drawFaces draws faces of the skin s onto dst, scaled so that the enlarged
+// overlay cube fits at any angle.
+func drawFaces(dst *image.NRGBA, s image.Image, faces []face) {
+	b := dst.Bounds()
+	// The projected overlay cube is at most 2.25 units high, and less wide.
+	k := float64(b.Dy()) / 2.25
+	cx, cy := float64(b.Min.X)+float64(b.Dx())/2, float64(b.Min.Y)+float64(b.Dy())/2
+	for _, f := range faces {
+		det := f.u[0]*f.v[1] - f.u[1]*f.v[0]
+		if det == 0 {
+			// The face is seen edge on.
+			continue
+		}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				qx := (float64(x)+0.5-cx)/k - f.origin[0]
+				qy := (float64(y)+0.5-cy)/k - f.origin[1]
+				// Solve q = a*u + b*v for the position on the face.
+				a := (qx*f.v[1] - qy*f.v[0]) / det
+				bb := (f.u[0]*qy - f.u[1]*qx) / det
+				if a < 0 || a >= 1 || bb < 0 || bb >= 1 {
+					continue
+				}
+				tx := f.tex.Min.X + int(a*float64(f.tex.Dx()))
+				ty := f.tex.Min.Y + int(bb*float64(f.tex.Dy()))
+				c := shade(color.NRGBAModel.Convert(s.At(tx, ty)).(color.NRGBA), f.shade)
+				dst.SetNRGBA(x, y, over(dst.NRGBAAt(x, y), c))
+			}
+		}
+	}
+}
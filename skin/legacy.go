@@ -0,0 +1,77 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// IsLegacy reports whether s uses the legacy 64x32 layout, which has no
+// separate textures for the left limbs and no overlay for the body and limbs.
+func IsLegacy(s image.Image) bool {
+	return s.Bounds().Dy()*2 == s.Bounds().Dx()
+}
+
+// legacyCopies lists the regions of a legacy skin which are mirrored to make
+// the left limbs of the modern layout, as the source x, y, the offset to the
+// destination, and the size, in skin pixels.
+var legacyCopies = [][6]int{
+	// Leg top and bottom, then its four sides.
+	{4, 16, 16, 32, 4, 4},
+	{8, 16, 16, 32, 4, 4},
+	{0, 20, 24, 32, 4, 12},
+	{4, 20, 16, 32, 4, 12},
+	{8, 20, 8, 32, 4, 12},
+	{12, 20, 16, 32, 4, 12},
+	// The same for the arm.
+	{44, 16, -8, 32, 4, 4},
+	{48, 16, -8, 32, 4, 4},
+	{40, 20, 0, 32, 4, 12},
+	{44, 20, -8, 32, 4, 12},
+	{48, 20, -16, 32, 4, 12},
+	{52, 20, -8, 32, 4, 12},
+}
+
+// ConvertLegacy upgrades a skin in the legacy 64x32 layout to the modern 64x64
+// one, the same way Minecraft does: the right arm and leg are mirrored to make
+// the left ones, the head overlay is cleared if it is completely opaque, and
+// every other part of the base layer is made opaque. Skins already in the
+// modern layout are returned unchanged.
+func ConvertLegacy(s image.Image) *image.NRGBA {
+	b := s.Bounds()
+	if !IsLegacy(s) {
+		dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), s, b.Min, draw.Src)
+		return dst
+	}
+	n := scale(s)
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dx()))
+	draw.Draw(dst, b.Sub(b.Min), s, b.Min, draw.Src)
+	setOpaque(dst, rect(dst, 0, 0, 32, 16))
+	if legacyOpaque(s, rect(s, 32, 0, 32, 16)) {
+		draw.Draw(dst, rect(dst, 32, 0, 32, 16), image.Transparent, image.Point{}, draw.Src)
+	}
+	setOpaque(dst, rect(dst, 0, 16, 64, 16))
+	for _, c := range legacyCopies {
+		src := rect(dst, c[0], c[1], c[4], c[5])
+		for y := src.Min.Y; y < src.Max.Y; y++ {
+			for x := src.Min.X; x < src.Max.X; x++ {
+				// Mirror horizontally within the region.
+				dx := src.Min.X + src.Max.X - 1 - x + c[2]*n
+				dst.SetNRGBA(dx, y+c[3]*n, dst.NRGBAAt(x, y))
+			}
+		}
+	}
+	setOpaque(dst, rect(dst, 16, 48, 32, 16))
+	return dst
+}
+
+// setOpaque removes the transparency of every pixel of dst in r.
+func setOpaque(dst *image.NRGBA, r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := dst.NRGBAAt(x, y)
+			dst.SetNRGBA(x, y, color.NRGBA{c.R, c.G, c.B, 0xff})
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package skin
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// Scale resizes img to w by h pixels with nearest neighbour sampling, which
+// keeps the edges of skin pixels sharp at any size.
+func Scale(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return dst
+}
+
+// Flatten draws img over a solid background of colour bg, for outputs which
+// do not support transparency well.
+func Flatten(img image.Image, bg color.Color) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+// An Encoder writes images in a file format.
+type Encoder struct {
+	// ContentType is the MIME type of the format.
+	ContentType string
+	// Encode writes img to w.
+	Encode func(w io.Writer, img image.Image) error
+}
+
+// PNG is the Encoder for PNG images.
+var PNG = Encoder{ContentType: "image/png", Encode: png.Encode}
@@ -0,0 +1,123 @@
+package skin
+
+import (
+	"image"
+	"image/draw"
+)
+
+// A Model is the shape of the player model a skin is made for.
+type Model int
+
+const (
+	// Classic is the original model, with arms four pixels wide.
+	Classic Model = iota
+	// Slim is the model with arms three pixels wide, used by skins whose
+	// texture has the "slim" model in its metadata.
+	Slim
+)
+
+// ModelOf returns the model named in the metadata of a skin texture, as in
+// mcaccutils.Texture.Model.
+func ModelOf(name string) Model {
+	if name == "slim" {
+		return Slim
+	}
+	return Classic
+}
+
+// A Part is one of the boxes making up the player model.
+type Part int
+
+const (
+	Head Part = iota
+	Body
+	RightArm
+	LeftArm
+	RightLeg
+	LeftLeg
+)
+
+// A Layer is one of the two layers of a skin.
+type Layer int
+
+const (
+	// Base is the inner layer, which is always opaque.
+	Base Layer = iota
+	// Overlay is the outer layer, such as the hat or the jacket, which may be
+	// transparent.
+	Overlay
+)
+
+// A Side is one of the faces of a Part. Right and Left are from the point of
+// view of the player.
+type Side int
+
+const (
+	Front Side = iota
+	Back
+	Top
+	Bottom
+	Right
+	Left
+)
+
+// A box describes where the texture of a part starts in the skin, and its
+// width, height and depth, in skin pixels.
+type box struct {
+	base, overlay image.Point
+	w, h, d       int
+}
+
+// boxes holds the layout of each Part for the classic model.
+var boxes = map[Part]box{
+	Head:     {image.Pt(0, 0), image.Pt(32, 0), 8, 8, 8},
+	Body:     {image.Pt(16, 16), image.Pt(16, 32), 8, 12, 4},
+	RightArm: {image.Pt(40, 16), image.Pt(40, 32), 4, 12, 4},
+	LeftArm:  {image.Pt(32, 48), image.Pt(48, 48), 4, 12, 4},
+	RightLeg: {image.Pt(0, 16), image.Pt(0, 32), 4, 12, 4},
+	LeftLeg:  {image.Pt(16, 48), image.Pt(0, 48), 4, 12, 4},
+}
+
+// Region returns the region of a skin holding the texture of side of part p
+// in layer l, in skin pixels, for the model m. Multiply it by the width of the
+// skin divided by 64 for high definition skins.
+func Region(m Model, p Part, l Layer, side Side) image.Rectangle {
+	b := boxes[p]
+	if m == Slim && (p == RightArm || p == LeftArm) {
+		b.w = 3
+	}
+	o := b.base
+	if l == Overlay {
+		o = b.overlay
+	}
+	// Every box is unwrapped the same way: the top and bottom above, and the
+	// right, front, left and back sides in a row below.
+	var x, y, w, h int
+	switch side {
+	case Top:
+		x, y, w, h = b.d, 0, b.w, b.d
+	case Bottom:
+		x, y, w, h = b.d+b.w, 0, b.w, b.d
+	case Right:
+		x, y, w, h = 0, b.d, b.d, b.h
+	case Front:
+		x, y, w, h = b.d, b.d, b.w, b.h
+	case Left:
+		x, y, w, h = b.d+b.w, b.d, b.d, b.h
+	case Back:
+		x, y, w, h = 2*b.d+b.w, b.d, b.w, b.h
+	}
+	return image.Rect(x, y, x+w, y+h).Add(o)
+}
+
+// Extract returns a copy of the texture of side of part p in layer l of the
+// skin s, which is made for the model m. Legacy skins have no textures for
+// the left limbs or for any overlay but the hat, so they should be converted
+// with ConvertLegacy first; parts outside s are returned transparent.
+func Extract(s image.Image, m Model, p Part, l Layer, side Side) *image.NRGBA {
+	r := Region(m, p, l, side)
+	src := rect(s, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	dst := image.NewNRGBA(image.Rect(0, 0, src.Dx(), src.Dy()))
+	draw.Draw(dst, dst.Bounds(), s, src.Min, draw.Src)
+	return dst
+}
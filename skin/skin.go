@@ -0,0 +1,71 @@
+// Package skin renders Minecraft player skins, such as those linked from the
+// textures of a mcaccutils.Profile.
+//
+// Skins are read in the modern 64x64 layout, or the legacy 64x32 one, and may
+// be high definition, in which case every measurement is scaled by the width
+// of the skin divided by 64.
+package skin
+
+import (
+	"image"
+	"image/color"
+)
+
+// scale returns the number of pixels per skin pixel of s.
+func scale(s image.Image) int {
+	if n := s.Bounds().Dx() / 64; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// rect returns the region of s covering the w by h skin pixels starting at x,
+// y in the standard layout.
+func rect(s image.Image, x, y, w, h int) image.Rectangle {
+	n := scale(s)
+	min := s.Bounds().Min
+	return image.Rect(x*n, y*n, (x+w)*n, (y+h)*n).Add(min)
+}
+
+// legacyOpaque reports whether s is a legacy skin with no transparent pixels
+// in the region r. Many legacy skins fill the unused overlay with a solid
+// colour, so Minecraft ignores the overlay of such skins, and so do the
+// renderers.
+func legacyOpaque(s image.Image, r image.Rectangle) bool {
+	if s.Bounds().Dy() >= s.Bounds().Dx() {
+		return false
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if _, _, _, a := s.At(x, y).RGBA(); a < 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// shade darkens c by the factor f, leaving its alpha alone.
+func shade(c color.NRGBA, f float64) color.NRGBA {
+	c.R = uint8(float64(c.R) * f)
+	c.G = uint8(float64(c.G) * f)
+	c.B = uint8(float64(c.B) * f)
+	return c
+}
+
+// over composites src over dst.
+func over(dst, src color.NRGBA) color.NRGBA {
+	if src.A == 0xff || dst.A == 0 {
+		return src
+	}
+	if src.A == 0 {
+		return dst
+	}
+	sa := float64(src.A) / 0xff
+	da := float64(dst.A) / 0xff * (1 - sa)
+	a := sa + da
+	mix := func(s, d uint8) uint8 {
+		return uint8((float64(s)*sa + float64(d)*da) / a)
+	}
+	return color.NRGBA{mix(src.R, dst.R), mix(src.G, dst.G), mix(src.B, dst.B), uint8(a * 0xff)}
+}
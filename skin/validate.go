@@ -0,0 +1,75 @@
+package skin
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// ErrHD is returned by Validate for skins which are valid, but larger than
+// the 64 pixels wide accepted by Mojang. The renderers in this package accept
+// them, so callers only rendering skins can ignore it.
+var ErrHD = errors.New("skin: high definition skin")
+
+// A SizeError is returned by Validate for skins whose dimensions do not match
+// either layout.
+type SizeError struct {
+	Width, Height int
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("skin: invalid size %dx%d, must be 64x64 or 64x32", e.Width, e.Height)
+}
+
+// A TransparencyError is returned by Validate for skins with a transparent
+// pixel in the base layer. Minecraft draws the base layer opaque, so such
+// pixels show up as whatever colour they happen to hold.
+type TransparencyError struct {
+	// X and Y are the position of the first transparent pixel found.
+	X, Y int
+}
+
+func (e *TransparencyError) Error() string {
+	return fmt.Sprintf("skin: transparent pixel at %d,%d in the base layer", e.X, e.Y)
+}
+
+// baseFaces returns the regions of the base layer which are drawn on the
+// model, in skin pixels. Legacy skins have no left limbs.
+func baseFaces(m Model, legacy bool) []image.Rectangle {
+	var faces []image.Rectangle
+	for _, p := range []Part{Head, Body, RightArm, LeftArm, RightLeg, LeftLeg} {
+		if legacy && (p == LeftArm || p == LeftLeg) {
+			continue
+		}
+		for _, side := range []Side{Front, Back, Top, Bottom, Right, Left} {
+			faces = append(faces, Region(m, p, Base, side))
+		}
+	}
+	return faces
+}
+
+// Validate checks that s is a well formed skin for the model m. It returns a
+// *SizeError if s has the wrong dimensions, ErrHD if it is a high definition
+// skin, and a *TransparencyError if any part of the base layer is
+// transparent.
+func Validate(s image.Image, m Model) error {
+	b := s.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || w%64 != 0 || (h != w && h*2 != w) {
+		return &SizeError{Width: w, Height: h}
+	}
+	for _, f := range baseFaces(m, IsLegacy(s)) {
+		r := rect(s, f.Min.X, f.Min.Y, f.Dx(), f.Dy())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if _, _, _, a := s.At(x, y).RGBA(); a < 0xffff {
+					return &TransparencyError{X: x - b.Min.X, Y: y - b.Min.Y}
+				}
+			}
+		}
+	}
+	if w > 64 {
+		return ErrHD
+	}
+	return nil
+}
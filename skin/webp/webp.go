@@ -0,0 +1,28 @@
+// Package webp provides a skin.Encoder for WebP images, kept apart from the
+// skin package so programs which only serve PNG do not depend on a WebP
+// encoder.
+//
+// The images are encoded losslessly, which keeps the edges of skin pixels
+// sharp and is usually smaller than PNG for renders. To serve them from a
+// skin.Handler, add the encoder to its Formats:
+//
+//	h := &skin.Handler{
+//		Client:  c,
+//		Formats: map[string]skin.Encoder{"webp": webp.Encoder},
+//	}
+package webp
+
+import (
+	"image"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/bearbin/go-mcaccutils/skin"
+)
+
+// Encoder is the skin.Encoder for lossless WebP images.
+var Encoder = skin.Encoder{ContentType: "image/webp", Encode: encode}
+
+func encode(w io.Writer, img image.Image) error {
+	return nativewebp.Encode(w, img, nil)
+}
@@ -0,0 +1,108 @@
+package mcaccutils
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// skinHash returns the hash of the skin of the player with the specified
+// UUID, or an empty string if they use a default skin.
+func (c *Client) skinHash(ctx context.Context, uuid string) (hash string, err error) {
+	p, err := c.GetProfileContext(ctx, uuid)
+	if err != nil {
+		return "", err
+	}
+	t, err := p.Textures()
+	if err != nil {
+		return "", err
+	}
+	if t.Skin == nil {
+		return "", nil
+	}
+	return t.Skin.Hash(), nil
+}
+
+// HasSkinChanged fetches the profile of the player with the specified UUID and
+// reports whether their skin is different to the one seen by the previous
+// call for the same player. The first call for each player only records the
+// skin, and reports false.
+//
+// Whenever a change is found, an EventSkinChanged event is published with the
+// hash of the new skin, which is empty if the player switched to a default
+// skin. Skins are tracked in memory, so they are forgotten when the program
+// exits.
+func (c *Client) HasSkinChanged(uuid string) (changed bool, err error) {
+	return c.hasSkinChanged(context.Background(), uuid)
+}
+
+// hasSkinChanged implements HasSkinChanged, giving up when ctx is done.
+func (c *Client) hasSkinChanged(ctx context.Context, uuid string) (changed bool, err error) {
+	uuid = strings.Replace(uuid, "-", "", -1)
+	hash, err := c.skinHash(ctx, uuid)
+	if err != nil {
+		return false, err
+	}
+	c.skinMu.Lock()
+	if c.skins == nil {
+		c.skins = make(map[string]string)
+	}
+	prev, seen := c.skins[uuid]
+	c.skins[uuid] = hash
+	c.skinMu.Unlock()
+	if !seen || prev == hash {
+		return false, nil
+	}
+	c.publish(Event{Type: EventSkinChanged, Query: uuid, UUID: uuid, SkinHash: hash})
+	return true, nil
+}
+
+// HasSkinChanged calls HasSkinChanged on the default client.
+func HasSkinChanged(uuid string) (changed bool, err error) {
+	return defaultClient.HasSkinChanged(uuid)
+}
+
+// WatchSkins starts a goroutine which checks the skins of the players with
+// the specified UUIDs in turn, one every interval, publishing an
+// EventSkinChanged event whenever one changes. Subscribe to the client to
+// receive them. Errors are ignored, and the player is checked again on the
+// next round. If interval is not positive, one minute is used. The interval
+// is measured by the clock of the client.
+//
+// The returned function stops the watcher, abandoning any check in progress.
+func (c *Client) WatchSkins(uuids []string, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go c.WatchSkinsContext(ctx, uuids, interval)
+	return cancel
+}
+
+// WatchSkinsContext is like WatchSkins, but runs in the calling goroutine
+// until ctx is done, abandoning any check in progress. It returns an error
+// matching ErrCanceled.
+func (c *Client) WatchSkinsContext(ctx context.Context, uuids []string, interval time.Duration) error {
+	uuids = append([]string(nil), uuids...)
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	clock := orSystem(c.Config().Clock)
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			return canceled(ctx)
+		case <-clock.After(interval):
+			if len(uuids) > 0 {
+				c.hasSkinChanged(ctx, uuids[i%len(uuids)])
+			}
+		}
+	}
+}
+
+// WatchSkins calls WatchSkins on the default client.
+func WatchSkins(uuids []string, interval time.Duration) (stop func()) {
+	return defaultClient.WatchSkins(uuids, interval)
+}
+
+// WatchSkinsContext calls WatchSkinsContext on the default client.
+func WatchSkinsContext(ctx context.Context, uuids []string, interval time.Duration) error {
+	return defaultClient.WatchSkinsContext(ctx, uuids, interval)
+}
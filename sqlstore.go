@@ -0,0 +1,235 @@
+package mcaccutils
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Dialect describes the differences between the SQL databases supported by
+// SQLStore, SQLHistory and SQLPlayerStore.
+type Dialect struct {
+	// Placeholder returns the bind parameter for the nth argument of a
+	// query, counting from one.
+	Placeholder func(n int) string
+	// BlobType is the column type used for binary values.
+	BlobType string
+}
+
+var (
+	// SQLite is the Dialect for SQLite databases.
+	SQLite = Dialect{
+		Placeholder: func(n int) string { return "?" },
+		BlobType:    "BLOB",
+	}
+
+	// Postgres is the Dialect for PostgreSQL databases.
+	Postgres = Dialect{
+		Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) },
+		BlobType:    "BYTEA",
+	}
+)
+
+// sqlMigrations holds the statements needed to bring the schema from each
+// version to the next. The schema version is the number of migrations that
+// have been applied.
+var sqlMigrations = []func(d Dialect) []string{
+	func(d Dialect) []string {
+		return []string{
+			"CREATE TABLE mcaccutils_cache (" +
+				"cache_key TEXT PRIMARY KEY, " +
+				"value " + d.BlobType + " NOT NULL, " +
+				"expires BIGINT NOT NULL)",
+			"CREATE INDEX mcaccutils_cache_expires ON mcaccutils_cache (expires)",
+		}
+	},
+	func(d Dialect) []string {
+		return []string{
+			"CREATE TABLE mcaccutils_history (" +
+				"query TEXT NOT NULL, " +
+				"uuid TEXT NOT NULL, " +
+				"name TEXT NOT NULL, " +
+				"name_key TEXT NOT NULL, " +
+				"not_found BOOLEAN NOT NULL, " +
+				"looked_up BIGINT NOT NULL)",
+			"CREATE INDEX mcaccutils_history_uuid ON mcaccutils_history (uuid, looked_up)",
+			"CREATE INDEX mcaccutils_history_name ON mcaccutils_history (name_key, looked_up)",
+		}
+	},
+	func(d Dialect) []string {
+		return []string{
+			"CREATE TABLE mcaccutils_queue (" +
+				"job TEXT NOT NULL, " +
+				"query TEXT NOT NULL, " +
+				"name_key TEXT NOT NULL, " +
+				"done BOOLEAN NOT NULL, " +
+				"uuid TEXT NOT NULL, " +
+				"name TEXT NOT NULL, " +
+				"not_found BOOLEAN NOT NULL, " +
+				"PRIMARY KEY (job, name_key))",
+			"CREATE INDEX mcaccutils_queue_done ON mcaccutils_queue (job, done)",
+		}
+	},
+	func(d Dialect) []string {
+		return []string{
+			"CREATE TABLE mcaccutils_players (" +
+				"uuid TEXT PRIMARY KEY, " +
+				"name TEXT NOT NULL, " +
+				"resolved BIGINT NOT NULL)",
+		}
+	},
+	func(d Dialect) []string {
+		return []string{
+			"ALTER TABLE mcaccutils_queue ADD COLUMN invalid TEXT NOT NULL DEFAULT ''",
+		}
+	},
+}
+
+// SQLStore is a Store which keeps entries in a SQL database through
+// database/sql. The caller is responsible for importing a driver.
+//
+// Entries are kept in the mcaccutils_cache table, which other services may
+// read directly: cache_key holds a lowercased username or an undashed UUID,
+// value holds the encoded player, and expires holds the expiry time as a Unix
+// timestamp.
+type SQLStore struct {
+	// Clock, if set, tells the time entries are expired by. It should be the
+	// Clock of the client using the store. Nil means the system clock.
+	Clock Clock
+
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSQLStore returns a SQLStore using the database db, which is of the
+// specified dialect. Migrate must have been called on the database before the
+// store is used.
+func NewSQLStore(db *sql.DB, d Dialect) *SQLStore {
+	return &SQLStore{db: db, dialect: d}
+}
+
+// Migrate creates or upgrades the tables used by the store. It is safe to call
+// every time the program starts.
+func (s *SQLStore) Migrate() error {
+	return MigrateSQL(s.db, s.dialect)
+}
+
+// SchemaVersion returns the version of the schema currently in the database.
+func (s *SQLStore) SchemaVersion() (version int, err error) {
+	return SQLSchemaVersion(s.db)
+}
+
+// MigrateSQL creates or upgrades all the tables used by the SQL backed types
+// of the package in db, which is of the specified dialect. It is safe to call
+// every time the program starts.
+func MigrateSQL(db *sql.DB, d Dialect) error {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS mcaccutils_schema (version INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("mcaccutils: creating schema table: %w", err)
+	}
+	version, err := SQLSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	for ; version < len(sqlMigrations); version++ {
+		if err := migrateSQL(db, d, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLSchemaVersion returns the version of the schema currently in db.
+func SQLSchemaVersion(db *sql.DB) (version int, err error) {
+	err = db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM mcaccutils_schema").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("mcaccutils: reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// migrateSQL applies the migration from the specified version to the next one
+// in a single transaction.
+func migrateSQL(db *sql.DB, d Dialect, version int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("mcaccutils: migrating schema to version %d: %w", version+1, err)
+	}
+	for _, stmt := range sqlMigrations[version](d) {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("mcaccutils: migrating schema to version %d: %w", version+1, err)
+		}
+	}
+	_, err = tx.Exec("INSERT INTO mcaccutils_schema (version) VALUES ("+d.Placeholder(1)+")", version+1)
+	if err == nil {
+		err = tx.Commit()
+	} else {
+		tx.Rollback()
+	}
+	if err != nil {
+		return fmt.Errorf("mcaccutils: migrating schema to version %d: %w", version+1, err)
+	}
+	return nil
+}
+
+// Ping implements StorePinger. It checks that the cache table can be read,
+// which also catches a database which has not been migrated.
+func (s *SQLStore) Ping(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT cache_key FROM mcaccutils_cache LIMIT 1")
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Get implements Store.
+func (s *SQLStore) Get(key string) (value []byte, found bool, err error) {
+	err = s.db.QueryRow(
+		"SELECT value FROM mcaccutils_cache WHERE cache_key = "+s.dialect.Placeholder(1)+
+			" AND expires > "+s.dialect.Placeholder(2),
+		key, orSystem(s.Clock).Now().Unix(),
+	).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("mcaccutils: reading %q from SQL store: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// Set implements Store.
+func (s *SQLStore) Set(key string, value []byte, expires time.Time) error {
+	_, err := s.db.Exec(
+		"INSERT INTO mcaccutils_cache (cache_key, value, expires) VALUES ("+
+			s.dialect.Placeholder(1)+", "+s.dialect.Placeholder(2)+", "+s.dialect.Placeholder(3)+") "+
+			"ON CONFLICT (cache_key) DO UPDATE SET value = excluded.value, expires = excluded.expires",
+		key, value, expires.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: writing %q to SQL store: %w", key, err)
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (s *SQLStore) Delete(key string) error {
+	_, err := s.db.Exec("DELETE FROM mcaccutils_cache WHERE cache_key = "+s.dialect.Placeholder(1), key)
+	if err != nil {
+		return fmt.Errorf("mcaccutils: deleting %q from SQL store: %w", key, err)
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired entries from the database. Expired entries
+// are never returned by Get, so calling it is only needed to reclaim space.
+func (s *SQLStore) DeleteExpired() error {
+	_, err := s.db.Exec("DELETE FROM mcaccutils_cache WHERE expires <= "+s.dialect.Placeholder(1), orSystem(s.Clock).Now().Unix())
+	if err != nil {
+		return fmt.Errorf("mcaccutils: deleting expired entries from SQL store: %w", err)
+	}
+	return nil
+}
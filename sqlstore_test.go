@@ -0,0 +1,130 @@
+package mcaccutils_test
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// postgresDSN names the environment variable holding the data source name of
+// a PostgreSQL database to run the SQL tests against as well. The tests drop
+// and recreate the tables of the package in it.
+const postgresDSN = "MCACCUTILS_TEST_POSTGRES"
+
+// forEachDialect runs f against a fresh SQLite database, and against the
+// PostgreSQL database named by postgresDSN if it is set.
+func forEachDialect(t *testing.T, f func(t *testing.T, db *sql.DB, d mcaccutils.Dialect)) {
+	t.Run("SQLite", func(t *testing.T) {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		f(t, db, mcaccutils.SQLite)
+	})
+	t.Run("Postgres", func(t *testing.T) {
+		dsn := os.Getenv(postgresDSN)
+		if dsn == "" {
+			t.Skip(postgresDSN + " is not set")
+		}
+		db, err := sql.Open("postgres", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		for _, table := range []string{"mcaccutils_schema", "mcaccutils_cache", "mcaccutils_history", "mcaccutils_queue", "mcaccutils_players"} {
+			if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+				t.Fatal(err)
+			}
+		}
+		f(t, db, mcaccutils.Postgres)
+	})
+}
+
+func TestMigrateSQL(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, d mcaccutils.Dialect) {
+		if err := mcaccutils.MigrateSQL(db, d); err != nil {
+			t.Fatal(err)
+		}
+		version, err := mcaccutils.SQLSchemaVersion(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version == 0 {
+			t.Fatal("schema version is 0 after migrating")
+		}
+		// Migrating again must be a no-op.
+		if err := mcaccutils.MigrateSQL(db, d); err != nil {
+			t.Fatalf("migrating again: %v", err)
+		}
+		if again, err := mcaccutils.SQLSchemaVersion(db); err != nil || again != version {
+			t.Errorf("schema version is %d, %v after migrating again, want %d", again, err, version)
+		}
+		for _, table := range []string{"mcaccutils_cache", "mcaccutils_history", "mcaccutils_queue", "mcaccutils_players"} {
+			rows, err := db.Query("SELECT * FROM " + table)
+			if err != nil {
+				t.Errorf("reading %s: %v", table, err)
+				continue
+			}
+			rows.Close()
+		}
+	})
+}
+
+func TestSQLStore(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, db *sql.DB, d mcaccutils.Dialect) {
+		s := mcaccutils.NewSQLStore(db, d)
+		if err := s.Migrate(); err != nil {
+			t.Fatal(err)
+		}
+		clock := mcaccutils.NewManualClock(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+		s.Clock = clock
+
+		if _, found, err := s.Get("notch"); err != nil || found {
+			t.Fatalf("Get on an empty store returned found %v, error %v", found, err)
+		}
+		if err := s.Set("notch", []byte("v1"), clock.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		// Setting a key again replaces its value.
+		if err := s.Set("notch", []byte("v2"), clock.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		v, found, err := s.Get("notch")
+		if err != nil || !found || !bytes.Equal(v, []byte("v2")) {
+			t.Fatalf("Get returned %q, %v, %v, want v2", v, found, err)
+		}
+
+		if err := s.Set("jeb_", []byte("v"), clock.Now().Add(2*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(90 * time.Minute)
+		if _, found, err := s.Get("notch"); err != nil || found {
+			t.Errorf("Get returned an entry expired by the clock of the store: found %v, error %v", found, err)
+		}
+		if err := s.DeleteExpired(); err != nil {
+			t.Fatal(err)
+		}
+		var n int
+		if err := db.QueryRow("SELECT COUNT(*) FROM mcaccutils_cache").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 {
+			t.Errorf("%d entries left after DeleteExpired, want 1", n)
+		}
+
+		if err := s.Delete("jeb_"); err != nil {
+			t.Fatal(err)
+		}
+		if _, found, err := s.Get("jeb_"); err != nil || found {
+			t.Errorf("Get after Delete returned found %v, error %v", found, err)
+		}
+	})
+}
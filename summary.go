@@ -0,0 +1,110 @@
+package mcaccutils
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// DefaultAvatarURL is the avatar URL template used when Config.AvatarURL is
+// empty.
+const DefaultAvatarURL = "https://crafatar.com/avatars/{uuid}"
+
+// A ProfileSummary is everything a Discord bot or a web page usually shows
+// about a player, in one value.
+type ProfileSummary struct {
+	// Name is the current username of the player.
+	Name string `json:"name"`
+	// UUID and DashedUUID are the UUID of the player without and with
+	// dashes.
+	UUID       string `json:"id"`
+	DashedUUID string `json:"uuid"`
+	// AvatarURL is the address of an image of the face of the player, made
+	// from Config.AvatarURL.
+	AvatarURL string `json:"avatarUrl"`
+	// SkinURL is the address of the skin texture of the player, or empty if
+	// they use a default skin. SkinModel is "slim" for skins using the slim
+	// arm model, and empty otherwise.
+	SkinURL   string `json:"skinUrl,omitempty"`
+	SkinModel string `json:"skinModel,omitempty"`
+	// NameChanges is the number of names in the name history of the player,
+	// or zero if the API no longer serves name histories, as with Mojang.
+	NameChanges int `json:"nameChanges"`
+	// Capes lists the names of the capes the player wears, as given by
+	// IdentifyCape. Capes missing from the catalog are listed as "Unknown".
+	// OptiFine capes are not included, as checking costs another request;
+	// HasOptiFineCape reports them.
+	Capes []string `json:"capes"`
+}
+
+// GetProfileSummary returns a summary of the player with the specified name
+// or UUID. Players named by UUID take a single request for their profile,
+// or none if it is in the response cache, and players named by username
+// another for their UUID, unless it is cached. The name history is only
+// fetched where the API still serves it.
+func (c *Client) GetProfileSummary(query string) (*ProfileSummary, error) {
+	return c.GetProfileSummaryContext(context.Background(), query)
+}
+
+// GetProfileSummaryContext is like GetProfileSummary, but gives up when ctx is
+// done.
+func (c *Client) GetProfileSummaryContext(ctx context.Context, query string) (*ProfileSummary, error) {
+	uuid := strings.ToLower(strings.Replace(query, "-", "", -1))
+	if _, err := parseUUID(uuid); err != nil {
+		if uuid, _, err = c.GetUUIDContext(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	p, err := c.GetProfileContext(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	s := &ProfileSummary{Name: p.Name, UUID: p.UUID, Capes: []string{}}
+	if s.DashedUUID, err = DashUUID(p.UUID); err != nil {
+		return nil, err
+	}
+	s.AvatarURL = c.avatarURL(p.UUID, p.Name)
+	t, err := p.Textures()
+	if err != nil && !errors.Is(err, ErrNoTextures) {
+		return nil, err
+	}
+	if t != nil && t.Skin != nil {
+		s.SkinURL, s.SkinModel = t.Skin.URL, t.Skin.Model
+	}
+	if t != nil && t.Cape != nil {
+		name := "Unknown"
+		if cape, ok := IdentifyCape(t.Cape.URL); ok {
+			name = cape.Name
+		}
+		s.Capes = append(s.Capes, name)
+	}
+	history, err := c.getNameHistory(ctx, p.UUID)
+	switch {
+	case err == nil:
+		s.NameChanges = len(history)
+	case !errors.Is(err, ErrEndpointRetired):
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetProfileSummary calls GetProfileSummary on the default client.
+func GetProfileSummary(query string) (*ProfileSummary, error) {
+	return defaultClient.GetProfileSummary(query)
+}
+
+// GetProfileSummaryContext calls GetProfileSummaryContext on the default
+// client.
+func GetProfileSummaryContext(ctx context.Context, query string) (*ProfileSummary, error) {
+	return defaultClient.GetProfileSummaryContext(ctx, query)
+}
+
+// avatarURL returns the avatar URL of the player with the specified undashed
+// UUID and name.
+func (c *Client) avatarURL(uuid, name string) string {
+	t := c.Config().AvatarURL
+	if t == "" {
+		t = DefaultAvatarURL
+	}
+	return strings.NewReplacer("{uuid}", uuid, "{name}", name).Replace(t)
+}
@@ -0,0 +1,179 @@
+package mcaccutils
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+)
+
+// ErrNoTextures is returned when a profile has no textures property.
+var ErrNoTextures = errors.New("mcaccutils: profile has no textures property")
+
+// Textures describes the skin and cape of a player, as held in the textures
+// property of their profile.
+type Textures struct {
+	// Timestamp is when the property was generated.
+	Timestamp time.Time
+	// ProfileID and ProfileName identify the player the textures belong to.
+	ProfileID   string
+	ProfileName string
+	// Skin and Cape are the textures the player is wearing. They are nil if
+	// the player uses a default skin, or has no cape.
+	Skin *Texture
+	Cape *Texture
+}
+
+// A Texture is a single texture of a player.
+type Texture struct {
+	// URL is the address of the texture image.
+	URL string
+	// Model is "slim" for skins using the slim arm model, and empty
+	// otherwise.
+	Model string
+}
+
+// Hash returns the hash identifying the texture image, which is the last
+// element of its URL.
+func (t *Texture) Hash() string {
+	return path.Base(t.URL)
+}
+
+type mojangTextures struct {
+	Timestamp   int64                    `json:"timestamp"`
+	ProfileID   string                   `json:"profileId"`
+	ProfileName string                   `json:"profileName"`
+	Textures    map[string]mojangTexture `json:"textures"`
+}
+
+type mojangTexture struct {
+	URL      string                 `json:"url"`
+	Metadata *mojangTextureMetadata `json:"metadata,omitempty"`
+}
+
+type mojangTextureMetadata struct {
+	Model string `json:"model"`
+}
+
+// Textures decodes the textures property of the profile.
+func (p *Profile) Textures() (*Textures, error) {
+	for _, prop := range p.Properties {
+		if prop.Name != "textures" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(prop.Value)
+		if err != nil {
+			return nil, fmt.Errorf("mcaccutils: decoding textures of %s: %w", p.UUID, err)
+		}
+		var dec mojangTextures
+		if err := json.Unmarshal(raw, &dec); err != nil {
+			return nil, fmt.Errorf("mcaccutils: decoding textures of %s: %w", p.UUID, err)
+		}
+		t := &Textures{
+			Timestamp:   time.Unix(0, dec.Timestamp*int64(time.Millisecond)),
+			ProfileID:   dec.ProfileID,
+			ProfileName: dec.ProfileName,
+		}
+		if skin, ok := dec.Textures["SKIN"]; ok {
+			t.Skin = &Texture{URL: skin.URL}
+			if skin.Metadata != nil {
+				t.Skin.Model = skin.Metadata.Model
+			}
+		}
+		if cape, ok := dec.Textures["CAPE"]; ok {
+			t.Cape = &Texture{URL: cape.URL}
+		}
+		return t, nil
+	}
+	return nil, ErrNoTextures
+}
+
+// Property encodes t as a textures property, which can be used to give custom
+// skulls and NPCs any skin. A zero Timestamp is replaced by the current time.
+//
+// If key is not nil, the property is signed with it the way Mojang signs
+// properties. Only the Mojang key is trusted by vanilla clients, so this is
+// only useful with servers or clients configured to trust key.
+func (t *Textures) Property(key *rsa.PrivateKey) (prop ProfileProperty, err error) {
+	ts := t.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	enc := mojangTextures{
+		Timestamp:   ts.UnixNano() / int64(time.Millisecond),
+		ProfileID:   t.ProfileID,
+		ProfileName: t.ProfileName,
+		Textures:    make(map[string]mojangTexture),
+	}
+	if t.Skin != nil {
+		skin := mojangTexture{URL: t.Skin.URL}
+		if t.Skin.Model != "" {
+			skin.Metadata = &mojangTextureMetadata{Model: t.Skin.Model}
+		}
+		enc.Textures["SKIN"] = skin
+	}
+	if t.Cape != nil {
+		enc.Textures["CAPE"] = mojangTexture{URL: t.Cape.URL}
+	}
+	raw, err := json.Marshal(enc)
+	if err != nil {
+		return ProfileProperty{}, fmt.Errorf("mcaccutils: encoding textures: %w", err)
+	}
+	prop = ProfileProperty{Name: "textures", Value: base64.StdEncoding.EncodeToString(raw)}
+	if key == nil {
+		return prop, nil
+	}
+	// Properties are signed over their base64 encoded value.
+	sum := sha1.Sum([]byte(prop.Value))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, sum[:])
+	if err != nil {
+		return ProfileProperty{}, fmt.Errorf("mcaccutils: signing textures: %w", err)
+	}
+	prop.Signature = base64.StdEncoding.EncodeToString(sig)
+	return prop, nil
+}
+
+// GetTexture downloads the image of the texture t, which is usually a PNG.
+// Images are cached by their hash for the SkinCacheDuration of the client.
+// Texture images never change, so callers may also cache them indefinitely.
+// They are served by a CDN rather than the Mojang API, so downloads do not
+// wait for or count towards the rate limit of the client.
+func (c *Client) GetTexture(t *Texture) (image []byte, err error) {
+	hash := t.Hash()
+	if b, found := c.textures.Get(hash); found {
+		c.countCache(CacheSkins, "", true)
+		return append([]byte(nil), b...), nil
+	}
+	c.countCache(CacheSkins, "", false)
+	ctx := context.WithValue(context.Background(), unlimitedKey{}, true)
+	resp, err := c.get(ctx, t.URL, hash)
+	if err != nil {
+		return nil, lookupError(t.URL, t.Hash(), err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, lookupError(t.URL, t.Hash(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, lookupError(t.URL, t.Hash(), statusError(resp))
+	}
+	if d := c.Config().SkinCacheDuration; d > 0 {
+		c.textures.Set(hash, append([]byte(nil), body...), d)
+	}
+	return body, nil
+}
+
+// GetTexture calls GetTexture on the default client.
+func GetTexture(t *Texture) (image []byte, err error) {
+	return defaultClient.GetTexture(t)
+}
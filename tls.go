@@ -0,0 +1,86 @@
+package mcaccutils
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// A PinError is returned when a server presents a certificate chain which
+// does not include any of the public keys pinned for its host.
+type PinError struct {
+	// Host is the name of the server.
+	Host string
+	// Presented holds the pin of every certificate in the presented chain.
+	Presented []string
+}
+
+func (e *PinError) Error() string {
+	return fmt.Sprintf("mcaccutils: certificate of %s does not match pinned keys (presented %s)",
+		e.Host, strings.Join(e.Presented, ", "))
+}
+
+// PublicKeyPin returns the pin of a certificate, in the form used by
+// Config.PinnedKeys: the base64 encoded SHA-256 hash of its
+// SubjectPublicKeyInfo.
+func PublicKeyPin(rawSubjectPublicKeyInfo []byte) string {
+	sum := sha256.Sum256(rawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verifyPins checks that one of the certificates presented in cs has a
+// public key pinned for its server, if there are any pins for it.
+func verifyPins(pins map[string][]string, cs tls.ConnectionState) error {
+	hostPins := pins[cs.ServerName]
+	if len(hostPins) == 0 {
+		return nil
+	}
+	presented := make([]string, len(cs.PeerCertificates))
+	for i, cert := range cs.PeerCertificates {
+		presented[i] = PublicKeyPin(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range hostPins {
+			if presented[i] == pin {
+				return nil
+			}
+		}
+	}
+	return &PinError{Host: cs.ServerName, Presented: presented}
+}
+
+// configureTLS applies the TLS settings of cfg to t.
+func configureTLS(cfg Config, t *http.Transport) {
+	if cfg.TLSConfig == nil && len(cfg.PinnedKeys) == 0 {
+		return
+	}
+	tlsCfg := &tls.Config{}
+	if cfg.TLSConfig != nil {
+		tlsCfg = cfg.TLSConfig.Clone()
+	}
+	if len(cfg.PinnedKeys) > 0 {
+		verify := tlsCfg.VerifyConnection
+		tlsCfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(cs); err != nil {
+					return err
+				}
+			}
+			return verifyPins(cfg.PinnedKeys, cs)
+		}
+	}
+	t.TLSClientConfig = tlsCfg
+}
+
+// errRoundTripper fails every request with err.
+type errRoundTripper struct {
+	err error
+}
+
+func (rt errRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, rt.err
+}
@@ -0,0 +1,107 @@
+package mcaccutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// ErrNoSession is returned by a TokenStore which has no session saved for an
+// account.
+var ErrNoSession = errors.New("mcaccutils: no saved session")
+
+// A TokenStore saves sessions between runs of a program, so users do not
+// have to log in every time.
+type TokenStore interface {
+	// Load returns the session saved for account, or ErrNoSession if there
+	// is none.
+	Load(account string) (*Session, error)
+	// Save saves s for account, replacing any saved session.
+	Save(account string, s *Session) error
+	// Delete removes the session saved for account, if any.
+	Delete(account string) error
+}
+
+// FileTokenStore is a TokenStore which keeps sessions in a JSON file that
+// only the user can read. The tokens are not encrypted, so programs which can
+// use the keyring package should prefer it.
+type FileTokenStore struct {
+	// Path is the location of the file. It is created when the first session
+	// is saved.
+	Path string
+
+	mu sync.Mutex
+}
+
+// read returns the sessions saved in the file, keyed by account.
+func (f *FileTokenStore) read() (map[string]*Session, error) {
+	sessions := make(map[string]*Session)
+	b, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading sessions: %w", err)
+	}
+	if err := json.Unmarshal(b, &sessions); err != nil {
+		return nil, fmt.Errorf("mcaccutils: reading sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// write replaces the contents of the file with sessions.
+func (f *FileTokenStore) write(sessions map[string]*Session) error {
+	b, err := json.MarshalIndent(sessions, "", "\t")
+	if err != nil {
+		return fmt.Errorf("mcaccutils: writing sessions: %w", err)
+	}
+	if err := ioutil.WriteFile(f.Path, b, 0600); err != nil {
+		return fmt.Errorf("mcaccutils: writing sessions: %w", err)
+	}
+	return nil
+}
+
+// Load implements TokenStore.
+func (f *FileTokenStore) Load(account string) (*Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	s, ok := sessions[account]
+	if !ok {
+		return nil, ErrNoSession
+	}
+	return s, nil
+}
+
+// Save implements TokenStore.
+func (f *FileTokenStore) Save(account string, s *Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions, err := f.read()
+	if err != nil {
+		return err
+	}
+	sessions[account] = s
+	return f.write(sessions)
+}
+
+// Delete implements TokenStore.
+func (f *FileTokenStore) Delete(account string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sessions, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := sessions[account]; !ok {
+		return nil
+	}
+	delete(sessions, account)
+	return f.write(sessions)
+}
@@ -0,0 +1,189 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+)
+
+// errTransportNotConfigurable is returned for every request of a Client
+// configured with TLS settings it cannot apply to its HTTP transport.
+var errTransportNotConfigurable = errors.New("mcaccutils: TLS settings need HTTPClient.Transport to be nil or an *http.Transport")
+
+// baseTransport applies the connection settings of cfg to rt, the transport
+// the client sends requests through. Tuning settings are only applied to an
+// *http.Transport, but a client with TLS settings which cannot be applied
+// fails every request rather than silently connecting without them.
+func baseTransport(cfg Config, rt http.RoundTripper) http.RoundTripper {
+	secure := cfg.TLSConfig != nil || len(cfg.PinnedKeys) > 0
+	tuned := cfg.MaxIdleConnsPerHost > 0 || cfg.IdleConnTimeout > 0 || cfg.TCPKeepAlive != 0 ||
+		cfg.DisableKeepAlives || cfg.DNSCacheDuration > 0
+	if !secure && !tuned {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		if secure {
+			return errRoundTripper{errTransportNotConfigurable}
+		}
+		return rt
+	}
+	t = t.Clone()
+	configureTLS(cfg, t)
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			t.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TCPKeepAlive != 0 || cfg.DNSCacheDuration > 0 {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: cfg.TCPKeepAlive}
+		t.DialContext = dialer.DialContext
+		if cfg.DNSCacheDuration > 0 {
+			t.DialContext = newDNSCache(cfg.DNSCacheDuration).dialer(dialer)
+		}
+	}
+	t.DisableKeepAlives = cfg.DisableKeepAlives
+	return t
+}
+
+// cachedResponse is a successful response held in a client's response cache.
+type cachedResponse struct {
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// response builds a new http.Response for req from the cached response.
+func (r *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+type transport struct {
+	c    *Client
+	base http.RoundTripper
+}
+
+// Transport returns an http.RoundTripper which sends requests through base,
+// or http.DefaultTransport if base is nil, sharing the rate limiter and
+// response cache of the client. Every request which reaches the network waits
+// for the rate limiter, and successful unauthenticated GET requests are served
+// from the response cache while they are fresh. Failed requests are retried
+// as described by Config.Retry.
+//
+// The client makes its own requests through a Transport, so wrapping the
+// transport of another library talking to Mojang services keeps the combined
+// request rate of the process within the limit.
+func (c *Client) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{c: c, base: base}
+}
+
+// releaseResponse arranges for release to be called once the body of resp has
+// been closed, or immediately if there is no response.
+func releaseResponse(resp *http.Response, err error, release func()) (*http.Response, error) {
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = releaseBody{resp.Body, release}
+	return resp, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.c.retry(req, t.roundTrip)
+}
+
+// roundTrip makes a single attempt at sending req.
+func (t *transport) roundTrip(req *http.Request) (*http.Response, error) {
+	d := t.c.Config().ResponseCacheDuration
+	// Authenticated responses belong to a single user, so never share them.
+	shareable := req.Method == http.MethodGet && req.Header.Get("Authorization") == "" &&
+		req.Header.Get("Cookie") == "" && !uncached(req.Context())
+	cacheable := d > 0 && shareable
+	key := req.URL.String()
+	if cacheable {
+		if r, found := t.c.responses.Get(key); found {
+			t.c.countCache(CacheResponses, "", true)
+			return r.response(req), nil
+		}
+	}
+	if shareable {
+		// Count misses even with the cache off, so CacheEfficiency can tell
+		// whether turning it on would help.
+		t.c.countCache(CacheResponses, "", false)
+	}
+	start := time.Now()
+	release, err := t.c.inflight.acquire(req.Context(), endpointOf(req))
+	if err != nil {
+		t.c.audit(req, start, time.Since(start), nil, err)
+		return nil, err
+	}
+	if unlimited(req.Context()) {
+		resp, err := t.base.RoundTrip(req)
+		t.c.audit(req, start, 0, resp, err)
+		t.c.latency.add(endpointOf(req), time.Since(start))
+		return releaseResponse(resp, err, release)
+	}
+	if err := t.c.limiter.Wait(req.Context()); err != nil {
+		release()
+		t.c.audit(req, start, time.Since(start), nil, err)
+		return nil, err
+	}
+	wait := time.Since(start)
+	t.c.metrics.add("api_calls", 1)
+	t.c.metrics.addTagged(TagFrom(req.Context()))
+	resp, err := t.base.RoundTrip(req)
+	t.c.audit(req, start, wait, resp, err)
+	t.c.latency.add(endpointOf(req), time.Since(start)-wait)
+	resp, err = releaseResponse(resp, err, release)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		t.c.limiter.Exceeded()
+		t.c.metrics.addError("rate_limited")
+		t.c.publish(Event{Type: EventRateLimited, Query: queryFrom(req.Context())})
+	} else {
+		t.c.limiter.Success()
+	}
+	if !cacheable || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.c.responses.Set(key, &cachedResponse{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+	}, d)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
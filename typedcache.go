@@ -0,0 +1,88 @@
+package mcaccutils
+
+import (
+	"time"
+
+	"github.com/pmylund/go-cache"
+)
+
+// typedCache is a memory cache holding values of a single type V. An entry of
+// any other type, which can only get there through a bug, is treated as
+// missing rather than panicking.
+//
+// Entries expire according to clock, but are only cleared out of memory once
+// they have also expired in real time.
+type typedCache[V any] struct {
+	c     *cache.Cache
+	clock Clock
+}
+
+// typedCacheEntry is a value stored in a typedCache, with its expiry time as
+// told by the clock of the cache.
+type typedCacheEntry[V any] struct {
+	v       V
+	expires time.Time
+}
+
+// typedCacheItem is an unexpired entry of a typedCache.
+type typedCacheItem[V any] struct {
+	Value   V
+	Expires time.Time
+}
+
+// newTypedCache returns an empty typedCache using clock, which clears out
+// expired entries at the given interval.
+func newTypedCache[V any](clock Clock, cleanupInterval time.Duration) *typedCache[V] {
+	// The default expiration is never used, as every entry is added with its
+	// own duration.
+	return &typedCache[V]{c: cache.New(1*time.Hour, cleanupInterval), clock: clock}
+}
+
+// entry returns the unexpired entry stored in x, if any.
+func (t *typedCache[V]) entry(x interface{}) (e typedCacheEntry[V], ok bool) {
+	e, ok = x.(typedCacheEntry[V])
+	return e, ok && t.clock.Now().Before(e.expires)
+}
+
+// Get returns the value stored under k, if any.
+func (t *typedCache[V]) Get(k string) (v V, found bool) {
+	x, found := t.c.Get(k)
+	if !found {
+		return v, false
+	}
+	e, ok := t.entry(x)
+	return e.v, ok
+}
+
+// Set stores v under k for the duration d, which must be positive.
+func (t *typedCache[V]) Set(k string, v V, d time.Duration) {
+	t.c.Set(k, typedCacheEntry[V]{v: v, expires: t.clock.Now().Add(d)}, d)
+}
+
+// Delete removes the value stored under k, if any.
+func (t *typedCache[V]) Delete(k string) {
+	t.c.Delete(k)
+}
+
+// Items returns all unexpired entries in the cache.
+func (t *typedCache[V]) Items() map[string]typedCacheItem[V] {
+	items := make(map[string]typedCacheItem[V])
+	for k, item := range t.c.Items() {
+		e, ok := t.entry(item.Object)
+		if !ok {
+			continue
+		}
+		items[k] = typedCacheItem[V]{Value: e.v, Expires: e.expires}
+	}
+	return items
+}
+
+// OnEvicted sets f to be called whenever an entry is removed from the cache,
+// either explicitly or because it expired.
+func (t *typedCache[V]) OnEvicted(f func(k string, v V)) {
+	t.c.OnEvicted(func(k string, x interface{}) {
+		if e, ok := x.(typedCacheEntry[V]); ok {
+			f(k, e.v)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package mcaccutils
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// parseUUID returns the bytes of a UUID, which may be dashed or undashed.
+func parseUUID(uuid string) (b [16]byte, err error) {
+	s := strings.Replace(uuid, "-", "", -1)
+	if len(s) != 32 {
+		return b, fmt.Errorf("mcaccutils: invalid UUID %q", uuid)
+	}
+	if _, err := hex.Decode(b[:], []byte(s)); err != nil {
+		return b, fmt.Errorf("mcaccutils: invalid UUID %q", uuid)
+	}
+	return b, nil
+}
+
+// formatUUID returns the undashed form of the UUID with bytes b.
+func formatUUID(b [16]byte) string {
+	return hex.EncodeToString(b[:])
+}
+
+// UUIDToInts splits a UUID, which may be dashed or undashed, into the four
+// integers used to store UUIDs in NBT since Minecraft 1.16, most significant
+// first.
+func UUIDToInts(uuid string) (ints [4]int32, err error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return ints, err
+	}
+	for i := range ints {
+		ints[i] = int32(binary.BigEndian.Uint32(b[i*4:]))
+	}
+	return ints, nil
+}
+
+// UUIDFromInts returns the undashed UUID stored in NBT as ints, the inverse
+// of UUIDToInts.
+func UUIDFromInts(ints [4]int32) string {
+	var b [16]byte
+	for i, n := range ints {
+		binary.BigEndian.PutUint32(b[i*4:], uint32(n))
+	}
+	return formatUUID(b)
+}
+
+// UUIDBytes returns the 16 bytes of a UUID, which may be dashed or undashed,
+// for storing in binary database columns or passing to other UUID libraries,
+// most of which define their UUID type as a [16]byte.
+func UUIDBytes(uuid string) (b [16]byte, err error) {
+	return parseUUID(uuid)
+}
+
+// UUIDFromBytes returns the undashed UUID with the bytes b, the inverse of
+// UUIDBytes.
+func UUIDFromBytes(b [16]byte) string {
+	return formatUUID(b)
+}
+
+// UUIDToBits splits a UUID, which may be dashed or undashed, into the two
+// signed 64 bit halves used by java.util.UUID and the UUIDMost and UUIDLeast
+// tags of NBT before Minecraft 1.16. Either half may be negative.
+func UUIDToBits(uuid string) (most, least int64, err error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b[:8])), int64(binary.BigEndian.Uint64(b[8:])), nil
+}
+
+// UUIDFromBits returns the undashed UUID with the most and least significant
+// bits given, the inverse of UUIDToBits.
+func UUIDFromBits(most, least int64) string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(most))
+	binary.BigEndian.PutUint64(b[8:], uint64(least))
+	return formatUUID(b)
+}
+
+// UUIDVersion returns the version of a UUID, which may be dashed or undashed:
+// 4 for the random UUIDs Mojang gives online accounts, and 3 for the name
+// based UUIDs servers in offline mode derive from usernames.
+func UUIDVersion(uuid string) (version int, err error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return 0, err
+	}
+	return int(b[6] >> 4), nil
+}
+
+// IsOnlineUUID reports whether uuid is a valid version 4 UUID, as given to
+// accounts by Mojang.
+func IsOnlineUUID(uuid string) bool {
+	v, err := UUIDVersion(uuid)
+	return err == nil && v == 4
+}
+
+// IsOfflineUUID reports whether uuid is a valid version 3 UUID, as derived
+// from the username by servers in offline mode. Players with such a UUID in
+// a world joined while the server was not authenticating them.
+func IsOfflineUUID(uuid string) bool {
+	v, err := UUIDVersion(uuid)
+	return err == nil && v == 3
+}
+
+// DashUUID returns a UUID, which may be dashed or undashed, in the dashed
+// form used by Java's UUID.toString and most file formats.
+func DashUUID(uuid string) (dashed string, err error) {
+	b, err := parseUUID(uuid)
+	if err != nil {
+		return "", err
+	}
+	s := formatUUID(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
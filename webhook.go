@@ -0,0 +1,79 @@
+package mcaccutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// webhookPayload is the JSON body posted by Webhook.
+type webhookPayload struct {
+	Type     string    `json:"type"`
+	Query    string    `json:"query,omitempty"`
+	UUID     string    `json:"id,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	OldName  string    `json:"oldName,omitempty"`
+	SkinHash string    `json:"skinHash,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Webhook returns a subscriber, to be passed to Subscribe, which posts the
+// events of the specified types to url as JSON objects, such as
+//
+//	{"type":"name_available","query":"Notch","name":"Notch","time":"..."}
+//
+// Every event type is posted if none are specified. The requests are sent in
+// the background with the HTTP client of the configuration, without going
+// through the rate limiter or middleware, and failures are ignored.
+func (c *Client) Webhook(url string, types ...EventType) func(e Event) {
+	hc := c.Config().HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	return func(e Event) {
+		if !hasEventType(types, e.Type) {
+			return
+		}
+		body, err := json.Marshal(webhookPayload{
+			Type:     e.Type.String(),
+			Query:    e.Query,
+			UUID:     e.UUID,
+			Name:     e.Name,
+			OldName:  e.OldName,
+			SkinHash: e.SkinHash,
+			Time:     e.Time,
+		})
+		if err != nil {
+			return
+		}
+		go func() {
+			resp, err := hc.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				return
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+}
+
+// Webhook calls Webhook on the default client.
+func Webhook(url string, types ...EventType) func(e Event) {
+	return defaultClient.Webhook(url, types...)
+}
+
+// hasEventType reports whether t is in types, or types is empty.
+func hasEventType(types []EventType, t EventType) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, tt := range types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
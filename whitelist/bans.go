@@ -0,0 +1,115 @@
+package whitelist
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// A Ban is an entry of banned-players.json, the list of players banned from
+// a server. The times are kept in the format the server writes them in, so
+// a file read into Bans is written back unchanged.
+type Ban struct {
+	// UUID is the dashed UUID of the player.
+	UUID string `json:"uuid"`
+	// Name is the name the player had when they were banned, unless it has
+	// been updated since.
+	Name string `json:"name"`
+	// Created is when the player was banned, such as
+	// "2024-01-02 15:04:05 +0000".
+	Created string `json:"created"`
+	// Source is who banned the player, usually an operator or "Server".
+	Source string `json:"source"`
+	// Expires is when the ban ends, or "forever".
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+}
+
+// ReadBans reads a ban list in the format of banned-players.json.
+func ReadBans(r io.Reader) ([]Ban, error) {
+	var bans []Ban
+	if err := json.NewDecoder(r).Decode(&bans); err != nil {
+		return nil, fmt.Errorf("whitelist: %w", err)
+	}
+	return bans, nil
+}
+
+// ReadBansFile reads the ban list in the file at path.
+func ReadBansFile(path string) ([]Ban, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadBans(f)
+}
+
+// WriteBans writes bans in the format of banned-players.json, indented as the
+// server does.
+func WriteBans(w io.Writer, bans []Ban) error {
+	if bans == nil {
+		bans = []Ban{}
+	}
+	data, err := json.MarshalIndent(bans, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteBansFile writes bans to the file at path in the format of
+// banned-players.json, replacing it atomically like WriteFile.
+func WriteBansFile(path string, bans []Ban) error {
+	var buf bytes.Buffer
+	if err := WriteBans(&buf, bans); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// UpdateBans looks up the current name of every player in the ban list at
+// path with c, and rewrites the file atomically if any have changed,
+// returning the renames, so ban screens and audits show the names players
+// go by now. Bans are tied to UUIDs, so renaming never lifts one.
+//
+// Like Update, it only changes the names of renamed players in the file as
+// it is just before writing, and players whose lookups fail keep their
+// names, with the error of the first returned along with the renames
+// applied.
+func UpdateBans(ctx context.Context, path string, c *mcaccutils.Client) (renames []Rename, err error) {
+	bans, err := ReadBansFile(path)
+	if err != nil {
+		return nil, err
+	}
+	players := make([]Entry, len(bans))
+	for i, b := range bans {
+		players[i] = Entry{UUID: b.UUID, Name: b.Name}
+	}
+	names, err := currentNames(ctx, c, players)
+	if len(names) == 0 {
+		return nil, err
+	}
+	bans, rerr := ReadBansFile(path)
+	if rerr != nil {
+		return nil, rerr
+	}
+	for i, b := range bans {
+		if name, ok := names[b.UUID]; ok && name != b.Name {
+			renames = append(renames, Rename{UUID: b.UUID, From: b.Name, To: name})
+			bans[i].Name = name
+		}
+	}
+	if len(renames) == 0 {
+		return nil, err
+	}
+	if werr := WriteBansFile(path, bans); werr != nil {
+		return nil, werr
+	}
+	return renames, err
+}
@@ -0,0 +1,127 @@
+package whitelist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// A Rename is a change to the name of a whitelisted or banned player.
+type Rename struct {
+	// UUID is the dashed UUID of the player, as in the whitelist.
+	UUID string
+	// From and To are the names before and after the change.
+	From string
+	To   string
+}
+
+// Update looks up the current name of every player in the whitelist at path
+// with c, and rewrites the file atomically if any have changed, returning
+// the renames. Names are looked up through the cache of c, so renames are
+// only seen once the old names expire from it, or are refreshed by its
+// refresher.
+//
+// The file is read again just before it is written, and only the names of
+// the renamed players are changed, so entries added by the server while the
+// names were looked up are kept. Players whose lookups fail keep their names,
+// and the error of the first is returned along with the renames applied.
+func Update(ctx context.Context, path string, c *mcaccutils.Client) (renames []Rename, err error) {
+	entries, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	names, err := currentNames(ctx, c, entries)
+	if len(names) == 0 {
+		return nil, err
+	}
+	entries, rerr := ReadFile(path)
+	if rerr != nil {
+		return nil, rerr
+	}
+	for i, e := range entries {
+		if name, ok := names[e.UUID]; ok && name != e.Name {
+			renames = append(renames, Rename{UUID: e.UUID, From: e.Name, To: name})
+			entries[i].Name = name
+		}
+	}
+	if len(renames) == 0 {
+		return nil, err
+	}
+	if werr := WriteFile(path, entries); werr != nil {
+		return nil, werr
+	}
+	return renames, err
+}
+
+// currentNames looks up the players in entries with c, and returns the
+// current names of those who have been renamed, by UUID. Players whose
+// lookups fail are left out, and the error of the first is returned, unless
+// the player no longer exists.
+func currentNames(ctx context.Context, c *mcaccutils.Client, entries []Entry) (names map[string]string, err error) {
+	names = make(map[string]string)
+	for _, e := range entries {
+		if e.UUID == "" {
+			continue
+		}
+		name, lerr := c.GetNameContext(ctx, e.UUID)
+		if errors.Is(lerr, mcaccutils.ErrCanceled) {
+			return nil, lerr
+		}
+		if lerr != nil {
+			if err == nil && !errors.Is(lerr, mcaccutils.ErrPlayerNotFound) {
+				err = fmt.Errorf("whitelist: looking up %s: %w", e.Name, lerr)
+			}
+			continue
+		}
+		if name != e.Name {
+			names[e.UUID] = name
+		}
+	}
+	return names, err
+}
+
+// Sync keeps the names in the whitelist at path up to date until ctx is
+// done, when it returns an error matching mcaccutils.ErrCanceled. It calls
+// Update every interval, and as soon as c reports a whitelisted player was
+// renamed, calling f, if it is not nil, with every rename written. Errors are
+// ignored, and the whitelist is updated again on the next round. If interval
+// is not positive, one hour is used.
+//
+// Running the refresher of c with StartRefresher spots the renames of
+// players who join often well before their cache entries expire.
+func Sync(ctx context.Context, path string, c *mcaccutils.Client, interval time.Duration, f func(r Rename)) error {
+	renamed := make(chan struct{}, 1)
+	unsubscribe := c.Subscribe(func(e mcaccutils.Event) {
+		if e.Type != mcaccutils.EventNameChanged {
+			return
+		}
+		// Update checks whether the player is whitelisted.
+		select {
+		case renamed <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		renames, _ := Update(ctx, path, c)
+		if f != nil {
+			for _, r := range renames {
+				f(r)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", mcaccutils.ErrCanceled, ctx.Err())
+		case <-ticker.C:
+		case <-renamed:
+		}
+	}
+}
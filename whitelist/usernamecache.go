@@ -0,0 +1,131 @@
+package whitelist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// UsernameCacheFile is the name of the file Forge servers record the last
+// name of every player who joined in, next to the usercache.json of the
+// server.
+const UsernameCacheFile = "usernamecache.json"
+
+// ReadUsernameCache reads the players in the format of Forge's
+// usernamecache.json, an object mapping dashed UUIDs to names, sorted by
+// name.
+func ReadUsernameCache(r io.Reader) ([]Entry, error) {
+	var names map[string]string
+	if err := json.NewDecoder(r).Decode(&names); err != nil {
+		return nil, fmt.Errorf("whitelist: %w", err)
+	}
+	entries := make([]Entry, 0, len(names))
+	for uuid, name := range names {
+		entries = append(entries, Entry{UUID: uuid, Name: name})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// ReadUsernameCacheFile reads the players in the usernamecache.json file at
+// path.
+func ReadUsernameCacheFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadUsernameCache(f)
+}
+
+// WriteUsernameCache writes entries in the format of usernamecache.json,
+// indented as Forge does. Entries without a UUID are left out.
+func WriteUsernameCache(w io.Writer, entries []Entry) error {
+	names := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if e.UUID != "" {
+			names[e.UUID] = e.Name
+		}
+	}
+	data, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteUsernameCacheFile writes entries to the file at path in the format of
+// usernamecache.json, replacing it atomically like WriteFile.
+func WriteUsernameCacheFile(path string, entries []Entry) error {
+	var buf bytes.Buffer
+	if err := WriteUsernameCache(&buf, entries); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// ImportUsernameCache adds the players in the usernamecache.json file at
+// path to the cache of c with Remember, so the players who have joined a
+// modded server are resolved without requests, and returns how many were
+// added. Entries with invalid UUIDs are skipped.
+//
+// Forge only updates a name when the player joins, so players who renamed
+// since are cached under their old names until the cache expires.
+func ImportUsernameCache(path string, c *mcaccutils.Client) (n int, err error) {
+	entries, err := ReadUsernameCacheFile(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		uuid := strings.ToLower(strings.Replace(e.UUID, "-", "", -1))
+		if _, err := mcaccutils.DashUUID(uuid); err != nil || e.Name == "" {
+			continue
+		}
+		c.Remember(uuid, e.Name)
+		n++
+	}
+	return n, nil
+}
+
+// ExportUsernameCache merges the players in the cache of c into the
+// usernamecache.json file at path, creating it if it does not exist, and
+// rewrites it atomically. Players in the file but not in the cache are kept,
+// and players in both are given the name from the cache, which is never
+// older than the last time they joined.
+//
+// The server rewrites the file as players join, so it should be exported to
+// while the server is stopped, or the server's changes since it started may
+// be lost.
+func ExportUsernameCache(path string, c *mcaccutils.Client) error {
+	entries, err := ReadUsernameCacheFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	index := make(map[string]int, len(entries))
+	for i, e := range entries {
+		index[strings.ToLower(strings.Replace(e.UUID, "-", "", -1))] = i
+	}
+	for _, p := range c.CachedPlayers() {
+		if i, ok := index[p.UUID]; ok {
+			entries[i].Name = p.Name
+			continue
+		}
+		uuid, err := mcaccutils.DashUUID(p.UUID)
+		if err != nil {
+			continue
+		}
+		index[p.UUID] = len(entries)
+		entries = append(entries, Entry{UUID: uuid, Name: p.Name})
+	}
+	return WriteUsernameCacheFile(path, entries)
+}
@@ -0,0 +1,168 @@
+// Package whitelist reads and writes the whitelist.json and
+// banned-players.json files of Minecraft servers, keeps the names in them up
+// to date as players rename, shares players with the usernamecache.json of
+// Forge servers, and converts the whitelists of offline mode
+// servers, whose UUIDs are derived from names, into whitelists for online
+// mode, resolving the names with a mcaccutils.Client.
+package whitelist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bearbin/go-mcaccutils"
+)
+
+// An Entry is a player on a whitelist.
+type Entry struct {
+	// UUID is the dashed UUID of the player. It is empty for entries read
+	// from a list of names.
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+// Read reads a whitelist, either in the JSON format of whitelist.json or as
+// a list of names, one on each line. Blank lines and lines starting with #
+// in a list of names are skipped.
+func Read(r io.Reader) ([]Entry, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		var entries []Entry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("whitelist: %w", err)
+		}
+		return entries, nil
+	}
+	var entries []Entry
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		name := strings.TrimSpace(s.Text())
+		if name == "" || strings.HasPrefix(name, "#") {
+			continue
+		}
+		entries = append(entries, Entry{Name: name})
+	}
+	return entries, s.Err()
+}
+
+// ReadFile reads the whitelist in the file at path.
+func ReadFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Write writes entries in the format of whitelist.json, indented as the
+// server does.
+func Write(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteFile writes entries to the file at path in the format of
+// whitelist.json. The file is replaced atomically, by writing a temporary
+// file beside it and renaming it over the original, so a server reading it
+// never sees half a whitelist.
+func WriteFile(path string, entries []Entry) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, entries); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic replaces the file at path with data, keeping the
+// permissions of the original if there is one.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Convert resolves the names of entries with c, looking them up in batches,
+// and returns an online mode whitelist with their current UUIDs and
+// correctly cased names, in the order of entries. The UUIDs of entries are
+// ignored, as an offline mode server derives them from names. Players listed
+// more than once appear once.
+//
+// Names which no longer belong to any player, or which no player can have,
+// are left out of the whitelist and returned in missing. If other lookups
+// fail, for example with an error matching mcaccutils.ErrRateLimited, their
+// names are in neither list, and the error of the first is returned along
+// with the rest of the results.
+func Convert(ctx context.Context, c *mcaccutils.Client, entries []Entry) (online []Entry, missing []string, err error) {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name
+	}
+	seen := make(map[string]bool)
+	online = []Entry{}
+	for _, r := range c.GetUUIDsContext(ctx, names) {
+		switch {
+		case errors.Is(r.Err, mcaccutils.ErrPlayerNotFound), errors.Is(r.Err, mcaccutils.ErrInvalidName):
+			missing = append(missing, r.Query)
+		case r.Err != nil:
+			if err == nil {
+				err = r.Err
+			}
+		case !seen[r.UUID]:
+			seen[r.UUID] = true
+			dashed, derr := mcaccutils.DashUUID(r.UUID)
+			if derr != nil {
+				if err == nil {
+					err = derr
+				}
+				continue
+			}
+			online = append(online, Entry{UUID: dashed, Name: r.Name})
+		}
+	}
+	return online, missing, err
+}